@@ -0,0 +1,239 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Blame configures the surviving code report of commit-count blame. Ref is
+// the revision blamed, HEAD by default. Exclude lists path globs that are
+// not counted; it replaces the default vendored and generated patterns.
+type Blame struct {
+	Ref     string
+	Exclude []string
+}
+
+var defaultBlameExclude []string = []string{
+	"vendor/**", "**/vendor/**", "Godeps/**", "**/Godeps/**", "node_modules/**", "**/node_modules/**",
+	"**/*.pb.go", "**/*_generated.go", "**/zz_generated*", "**/*.min.js", "**/*.min.css",
+	"**/Gemfile.lock", "**/package-lock.json", "**/yarn.lock", "**/go.sum",
+}
+
+// BlameAuthor is one author identity of a blame, as written by git.
+type BlameAuthor struct {
+	Name string
+	Mail string
+}
+
+// scanBlamePorcelain calls handle for every line of git blame
+// --line-porcelain output, which repeats the author headers for every line.
+func scanBlamePorcelain(output string, handle func(author BlameAuthor, authorTime time.Time)) {
+	var author BlameAuthor
+	var authorTime time.Time
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(line, "author ") {
+			author = BlameAuthor{Name: strings.TrimPrefix(line, "author ")}
+		} else if strings.HasPrefix(line, "author-mail ") {
+			author.Mail = strings.TrimPrefix(line, "author-mail ")
+		} else if strings.HasPrefix(line, "author-time ") {
+			seconds, _ := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64)
+			authorTime = time.Unix(seconds, 0).UTC()
+		} else if strings.HasPrefix(line, "author-tz ") {
+			if offset, err := time.Parse("-0700", strings.TrimPrefix(line, "author-tz ")); err == nil {
+				authorTime = authorTime.In(offset.Location())
+			}
+		} else if strings.HasPrefix(line, "\t") {
+			handle(author, authorTime)
+		}
+	}
+}
+
+// ParseBlamePorcelain counts the lines per author.
+func ParseBlamePorcelain(output string) map[BlameAuthor]int {
+	var result map[BlameAuthor]int = make(map[BlameAuthor]int)
+	scanBlamePorcelain(output, func(author BlameAuthor, authorTime time.Time) {
+		result[author]++
+	})
+	return result
+}
+
+// AsCommit turns a blame author into the GitCommit ReadCommit would produce
+// for the same "Author:" line, so identities match as in IsEmcCommit.
+func (author BlameAuthor) AsCommit() GitCommit {
+	var line string = "Author: " + author.Name + " " + author.Mail
+
+	isTwoAuthorPattern, name, coauthor := IsTwoAuthorPattern(line)
+	if isTwoAuthorPattern {
+		return GitCommit{Author: name, CoAuthor: coauthor}
+	}
+	if len(strings.Split(line, " ")) < 3 {
+		return GitCommit{Author: author.Name, AuthorDomain: GetEmailDomain(line)}
+	}
+	return GitCommit{Author: GetAuthor(line), AuthorDomain: GetEmailDomain(line)}
+}
+
+type SurvivingLines struct {
+	Lines         int
+	Contributors  map[string]int
+	Organizations map[string]int
+}
+
+// CountSurvivingLines credits blamed lines to the matching configured
+// contributor and to the author's organization.
+func CountSurvivingLines(setting Setting, blamed map[BlameAuthor]int) SurvivingLines {
+	var result SurvivingLines = SurvivingLines{
+		Contributors:  make(map[string]int),
+		Organizations: make(map[string]int),
+	}
+
+	for author, lines := range blamed {
+		var commit GitCommit = author.AsCommit()
+		result.Lines += lines
+		if isEmcCommit, contributorName := IsEmcCommit(commit, setting.Contributors); isEmcCommit {
+			result.Contributors[contributorName] += lines
+		}
+		result.Organizations[OrganizationOfDomain(setting, commit.AuthorDomain)] += lines
+	}
+	return result
+}
+
+// BlameFiles lists the text files of the ref that are included, or every
+// one without include, and not excluded.
+func BlameFiles(dir string, ref string, include []string, exclude []string) ([]string, error) {
+	// git grep -I skips binary files
+	output, err := gitOutput(dir, "grep", "-I", "--name-only", "-e", "", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, line := range strings.Split(output, "\n") {
+		var filePath string = strings.TrimPrefix(line, ref+":")
+		if filePath == "" || !IncludesPath(filePath, include, exclude) {
+			continue
+		}
+		result = append(result, filePath)
+	}
+	return result, nil
+}
+
+// blameEachFile runs git blame --line-porcelain on every file of the ref
+// and hands the output to handle.
+func blameEachFile(dir string, ref string, include []string, exclude []string, handle func(output string)) error {
+	files, err := BlameFiles(dir, ref, include, exclude)
+	if err != nil {
+		return err
+	}
+
+	for _, filePath := range files {
+		output, err := gitOutput(dir, "blame", "--line-porcelain", ref, "--", filePath)
+		if err != nil {
+			return err
+		}
+		handle(output)
+	}
+	return nil
+}
+
+func BlameRepository(dir string, ref string, include []string, exclude []string) (map[BlameAuthor]int, error) {
+	var result map[BlameAuthor]int = make(map[BlameAuthor]int)
+	err := blameEachFile(dir, ref, include, exclude, func(output string) {
+		for author, lines := range ParseBlamePorcelain(output) {
+			result[author] += lines
+		}
+	})
+	return result, err
+}
+
+func (setting Setting) blame() Blame {
+	var result Blame = setting.Blame
+	if result.Ref == "" {
+		result.Ref = "HEAD"
+	}
+	if len(result.Exclude) == 0 {
+		result.Exclude = defaultBlameExclude
+	}
+	return result
+}
+
+// excludeFor adds the repository's own exclude globs to those of blame, so
+// blame sees the same paths as the other reports of the repository.
+func (blame Blame) excludeFor(repo Repository) []string {
+	return append(append([]string{}, repo.Exclude...), blame.Exclude...)
+}
+
+// RunBlame blames every configured repository at the ref and writes the
+// surviving lines per contributor and organization to work/blame.csv.
+func RunBlame(setting Setting, options RunOptions) {
+	var blame Blame = setting.blame()
+
+	var result map[string]SurvivingLines = make(map[string]SurvivingLines)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for _, repo := range setting.Repositories {
+		wg.Add(1)
+		go func(repo1 Repository) {
+			defer wg.Done()
+
+			if !options.Offline {
+				if err := fetchSource(repo1); err != nil {
+					fmt.Printf("ERROR FETCH: %s\n", repo1.Name)
+					panic(err)
+				}
+			}
+
+			fmt.Printf("Blaming %s at %s\n", repo1.Name, blame.Ref)
+			blamed, err := BlameRepository(repoDir(repo1), blame.Ref, repo1.Include, blame.excludeFor(repo1))
+			if err != nil {
+				fmt.Printf("WARNING: unable to blame %s: %s\n", repo1.Name, err)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result[repo1.Name] = CountSurvivingLines(setting, blamed)
+		}(repo)
+	}
+	wg.Wait()
+
+	CreateBlameOutputFile(setting, blame.Ref, result)
+}
+
+func CreateBlameOutputFile(setting Setting, ref string, result map[string]SurvivingLines) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var repoNames []string
+	for repoName := range result {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	writer.Write([]string{"Code Repo", "Ref", "Owner Type", "Owner", "Lines", "Share"})
+	for _, repoName := range repoNames {
+		var surviving SurvivingLines = result[repoName]
+		for _, owners := range []struct {
+			ownerType string
+			lines     map[string]int
+		}{
+			{"contributor", surviving.Contributors},
+			{"organization", surviving.Organizations},
+		} {
+			for _, owner := range sortedByCount(owners.lines) {
+				writer.Write([]string{repoName, ref, owners.ownerType, owner,
+					strconv.Itoa(owners.lines[owner]), percentOf(owners.lines[owner], surviving.Lines)})
+			}
+		}
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/blame.csv", buffer.Bytes(), 0644)
+}
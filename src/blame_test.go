@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testBlamePorcelain = `a39b69d7e6ab6c59c76102136815c6b7ae578804 1 1 2
+author Victor Fong
+author-mail <victor.fong@emc.com>
+author-time 1445024589
+author-tz -0400
+summary Add instructions
+filename README.md
+	# Bosh
+a39b69d7e6ab6c59c76102136815c6b7ae578804 2 2
+author Victor Fong
+author-mail <victor.fong@emc.com>
+author-time 1445024589
+author-tz -0400
+summary Add instructions
+filename README.md
+	
+4d4033620e0c7280c8354504358a17b510c32e3f 3 3 1
+author Chris Piraino and Yu Zhang
+author-mail <cpiraino@pivotal.io>
+author-time 1445024589
+author-tz -0400
+summary Pair on docs
+filename README.md
+	Run the tests
+`
+
+func TestParseBlamePorcelain(t *testing.T) {
+	var result map[BlameAuthor]int = ParseBlamePorcelain(testBlamePorcelain)
+
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 2, result[BlameAuthor{Name: "Victor Fong", Mail: "<victor.fong@emc.com>"}])
+	assert.Equal(t, 1, result[BlameAuthor{Name: "Chris Piraino and Yu Zhang", Mail: "<cpiraino@pivotal.io>"}])
+}
+
+func TestScanBlamePorcelain_AuthorTime(t *testing.T) {
+	var times []time.Time
+	scanBlamePorcelain(testBlamePorcelain, func(author BlameAuthor, authorTime time.Time) {
+		times = append(times, authorTime)
+	})
+
+	assert.Equal(t, 3, len(times))
+	assert.Equal(t, "2015-10-16T15:43:09-04:00", times[0].Format(time.RFC3339))
+}
+
+func TestBlameAuthor_AsCommit(t *testing.T) {
+	var commit GitCommit = BlameAuthor{Name: "Victor Fong", Mail: "<victor.fong@emc.com>"}.AsCommit()
+	assert.Equal(t, "Victor Fong", commit.Author)
+	assert.Equal(t, "emc.com", commit.AuthorDomain)
+
+	commit = BlameAuthor{Name: "Chris Piraino and Yu Zhang", Mail: "<cpiraino@pivotal.io>"}.AsCommit()
+	assert.Equal(t, "Chris Piraino", commit.Author)
+	assert.Equal(t, "Yu Zhang", commit.CoAuthor)
+}
+
+func TestCountSurvivingLines(t *testing.T) {
+	var setting Setting = Setting{
+		Contributors:  []Contributor{{Name: "Yu Zhang"}},
+		Organizations: []Organization{{Name: "EMC", Domains: []string{"emc.com"}}},
+	}
+	var result SurvivingLines = CountSurvivingLines(setting, ParseBlamePorcelain(testBlamePorcelain))
+
+	assert.Equal(t, 3, result.Lines)
+	assert.Equal(t, map[string]int{"Yu Zhang": 1}, result.Contributors)
+	// The two author pattern has no domain, as in ReadCommit
+	assert.Equal(t, map[string]int{"EMC": 2, "Other": 1}, result.Organizations)
+}
+
+func TestDefaultBlameExclude(t *testing.T) {
+	assert.True(t, matchAny(defaultBlameExclude, "vendor/github.com/a/b.go"))
+	assert.True(t, matchAny(defaultBlameExclude, "src/github.com/x/Godeps/_workspace/a.go"))
+	assert.True(t, matchAny(defaultBlameExclude, "api/router.pb.go"))
+	assert.False(t, matchAny(defaultBlameExclude, "src/router.go"))
+}
+
+func TestBlame_ExcludeFor(t *testing.T) {
+	var blame Blame = Blame{Exclude: []string{"vendor/**"}}
+	var repo Repository = Repository{Include: []string{"src/**"}, Exclude: []string{"docs/**"}}
+
+	assert.Equal(t, []string{"docs/**", "vendor/**"}, blame.excludeFor(repo))
+	assert.Equal(t, []string{"vendor/**"}, blame.Exclude)
+	assert.Equal(t, []string{"vendor/**"}, blame.excludeFor(Repository{}))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Concentration sets when a repository is flagged as dominated: when its
+// top author or top organization has more than the given percentage of
+// commits.
+type Concentration struct {
+	AuthorShare       float64 `yaml:"author_share"`
+	OrganizationShare float64 `yaml:"organization_share"`
+}
+
+var defaultConcentration Concentration = Concentration{AuthorShare: 50, OrganizationShare: 75}
+
+type BusFactorReport struct {
+	Commits                int
+	Authors50              int
+	Authors80              int
+	ChurnAuthors50         int
+	ChurnAuthors80         int
+	Organizations50        int
+	TopAuthor              string
+	TopAuthorShare         float64
+	TopOrganization        string
+	TopOrganizationShare   float64
+	SingleAuthorDominated  bool
+	SingleCompanyDominated bool
+}
+
+func commitChurn(commit GitCommit) int {
+	var result int = 0
+	for _, file := range commit.Files {
+		result += file.Added + file.Deleted
+	}
+	return result
+}
+
+// BusFactor is the minimum number of keys whose weights add up to at least
+// the fraction of the total weight.
+func BusFactor(weights map[string]int, fraction float64) int {
+	var total int = 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return 0
+	}
+
+	var covered int = 0
+	for i, key := range sortedByCount(weights) {
+		covered += weights[key]
+		if float64(covered) >= fraction*float64(total) {
+			return i + 1
+		}
+	}
+	return len(weights)
+}
+
+func topShare(weights map[string]int) (string, float64) {
+	var total int = 0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return "", 0
+	}
+
+	var top string = sortedByCount(weights)[0]
+	return top, 100 * float64(weights[top]) / float64(total)
+}
+
+// BuildBusFactor measures how concentrated the commits and churn of a
+// repository are. Authors and co-authors are both credited, organizations
+// only for commits with an email domain.
+func BuildBusFactor(setting Setting, commits []GitCommit) BusFactorReport {
+	var authorCommits map[string]int = make(map[string]int)
+	var authorChurn map[string]int = make(map[string]int)
+	var organizationCommits map[string]int = make(map[string]int)
+
+	credit := func(author string, domain string, churn int) {
+		if author == "" {
+			return
+		}
+		authorCommits[author]++
+		authorChurn[author] += churn
+		// Pairs like "A and B" have no domain to tell their organization
+		if domain != "" {
+			organizationCommits[OrganizationOfDomain(setting, domain)]++
+		}
+	}
+	for _, commit := range commits {
+		var churn int = commitChurn(commit)
+		credit(commit.Author, commit.AuthorDomain, churn)
+		credit(commit.CoAuthor, commit.CoAuthorDomain, churn)
+	}
+
+	var result BusFactorReport = BusFactorReport{
+		Commits:         len(commits),
+		Authors50:       BusFactor(authorCommits, 0.5),
+		Authors80:       BusFactor(authorCommits, 0.8),
+		ChurnAuthors50:  BusFactor(authorChurn, 0.5),
+		ChurnAuthors80:  BusFactor(authorChurn, 0.8),
+		Organizations50: BusFactor(organizationCommits, 0.5),
+	}
+	result.TopAuthor, result.TopAuthorShare = topShare(authorCommits)
+	result.TopOrganization, result.TopOrganizationShare = topShare(organizationCommits)
+
+	var concentration Concentration = setting.Concentration
+	if concentration.AuthorShare == 0 {
+		concentration.AuthorShare = defaultConcentration.AuthorShare
+	}
+	if concentration.OrganizationShare == 0 {
+		concentration.OrganizationShare = defaultConcentration.OrganizationShare
+	}
+	result.SingleAuthorDominated = result.TopAuthorShare > concentration.AuthorShare
+	result.SingleCompanyDominated = result.TopOrganizationShare > concentration.OrganizationShare
+
+	return result
+}
+
+// Flag names every concentration the report is dominated by.
+func (report BusFactorReport) Flag() string {
+	var flags []string
+	if report.SingleAuthorDominated {
+		flags = append(flags, "single author")
+	}
+	if report.SingleCompanyDominated {
+		flags = append(flags, "single company")
+	}
+	return strings.Join(flags, "; ")
+}
+
+func formatShare(share float64) string {
+	return strconv.FormatFloat(share, 'f', 1, 64)
+}
+
+func CreateBusFactorOutputFile(setting Setting, history map[string][]GitCommit) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var repoNames []string
+	for repoName := range history {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	writer.Write([]string{"Window", "Code Repo", "Commits",
+		"Bus Factor 50%", "Bus Factor 80%", "Churn Bus Factor 50%", "Churn Bus Factor 80%", "Organizations 50%",
+		"Top Author", "Top Author Share", "Top Organization", "Top Organization Share", "Flag"})
+	for _, window := range setting.Windows {
+		for _, repoName := range repoNames {
+			var report BusFactorReport = BuildBusFactor(setting, CommitsInWindow(history[repoName], window))
+			if report.Commits == 0 {
+				continue
+			}
+
+			writer.Write([]string{window.Name, repoName, strconv.Itoa(report.Commits),
+				strconv.Itoa(report.Authors50), strconv.Itoa(report.Authors80),
+				strconv.Itoa(report.ChurnAuthors50), strconv.Itoa(report.ChurnAuthors80),
+				strconv.Itoa(report.Organizations50),
+				report.TopAuthor, formatShare(report.TopAuthorShare),
+				report.TopOrganization, formatShare(report.TopOrganizationShare), report.Flag()})
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/bus_factor.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusFactor(t *testing.T) {
+	var weights map[string]int = map[string]int{"a": 5, "b": 3, "c": 1, "d": 1}
+
+	assert.Equal(t, 1, BusFactor(weights, 0.5))
+	assert.Equal(t, 2, BusFactor(weights, 0.8))
+	assert.Equal(t, 4, BusFactor(weights, 1))
+	assert.Equal(t, 0, BusFactor(map[string]int{}, 0.5))
+}
+
+func TestBuildBusFactor(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_leaderboard_data))
+	var commits []GitCommit = readNumstatCommits()
+
+	var report BusFactorReport = BuildBusFactor(setting, commits)
+	assert.Equal(t, 3, report.Commits)
+	// Marco 2, Felix 1, Beyhan 1 commits; churn Marco 26, Felix 16, Beyhan 2
+	assert.Equal(t, 1, report.Authors50)
+	assert.Equal(t, 3, report.Authors80)
+	assert.Equal(t, 1, report.ChurnAuthors50)
+	assert.Equal(t, 2, report.ChurnAuthors80)
+	assert.Equal(t, 1, report.Organizations50)
+	assert.Equal(t, "Marco Voelz", report.TopAuthor)
+	assert.Equal(t, 50.0, report.TopAuthorShare)
+	assert.Equal(t, "SAP", report.TopOrganization)
+	assert.False(t, report.SingleAuthorDominated)
+	assert.True(t, report.SingleCompanyDominated)
+	assert.Equal(t, "single company", report.Flag())
+
+	setting.Concentration = Concentration{AuthorShare: 40}
+	assert.True(t, BuildBusFactor(setting, commits).SingleAuthorDominated)
+	assert.Equal(t, "single author; single company", BuildBusFactor(setting, commits).Flag())
+}
+
+func TestBuildBusFactor_PairWithoutDomain(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_leaderboard_data))
+	var commits []GitCommit = []GitCommit{
+		{Author: "Chris Piraino and Yu Zhang"},
+		{Author: "Chris Piraino and Yu Zhang"},
+		{Author: "Marco Voelz", AuthorDomain: "sap.com"},
+	}
+
+	var report BusFactorReport = BuildBusFactor(setting, commits)
+	assert.Equal(t, "SAP", report.TopOrganization)
+	assert.Equal(t, 100.0, report.TopOrganizationShare)
+	assert.Equal(t, 1, report.Organizations50)
+}
+
+func TestCommitsInWindow(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+
+	assert.Equal(t, 2, len(CommitsInWindow(commits, Window{Begin: "2015-12-28", End: "2016-01-01"})))
+}
+
+func TestCommitsInWindow_Timezone(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+
+	// The evening commits of Dec 28 in +0100 fall on Dec 29 in +0900
+	reportingLocation = time.FixedZone("JST", 9*60*60)
+	defer func() { reportingLocation = nil }()
+	assert.Equal(t, 4, len(CommitsInWindow(commits, Window{Begin: "2015-12-28", End: "2016-01-01"})))
+}
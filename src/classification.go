@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	categoryFeature = "feature"
+	categoryBugfix  = "bugfix"
+	categoryDocs    = "docs"
+	categoryTest    = "test"
+	categoryChore   = "chore"
+	categoryOther   = "other"
+)
+
+// Classification configures how commits are tagged with a category. Rules
+// are tried in order: Conventional Commit prefix, keywords, then paths.
+type Classification struct {
+	Keywords []KeywordRule
+	Paths    []PathRule
+}
+
+// KeywordRule matches when the description contains one of the words.
+type KeywordRule struct {
+	Category string
+	Words    []string
+}
+
+// PathRule matches when every file of the commit matches one of the
+// patterns, so a commit touching both docs and code is not docs.
+type PathRule struct {
+	Category string
+	Patterns []string
+}
+
+var defaultKeywordRules []KeywordRule = []KeywordRule{
+	{Category: categoryBugfix, Words: []string{"fix", "fixes", "fixed", "bug", "bugfix", "hotfix"}},
+	{Category: categoryChore, Words: []string{"bump", "merge", "release", "upgrade", "cleanup"}},
+}
+
+var defaultPathRules []PathRule = []PathRule{
+	{Category: categoryDocs, Patterns: []string{"docs/**", "**/*.md"}},
+	{Category: categoryTest, Patterns: []string{"**/*_test.go", "**/spec/**", "**/test/**", "**/tests/**"}},
+}
+
+var conventionalTypes map[string]string = map[string]string{
+	"feat":     categoryFeature,
+	"feature":  categoryFeature,
+	"fix":      categoryBugfix,
+	"bugfix":   categoryBugfix,
+	"docs":     categoryDocs,
+	"doc":      categoryDocs,
+	"test":     categoryTest,
+	"tests":    categoryTest,
+	"chore":    categoryChore,
+	"build":    categoryChore,
+	"ci":       categoryChore,
+	"refactor": categoryChore,
+	"style":    categoryChore,
+	"perf":     categoryChore,
+	"revert":   categoryChore,
+}
+
+var conventionalPattern = regexp.MustCompile(`^(\w+)(?:\([^)]*\))?!?:`)
+var wordPattern = regexp.MustCompile(`[A-Za-z]+`)
+
+func ClassifyCommit(commit GitCommit, classification Classification) string {
+	if match := conventionalPattern.FindStringSubmatch(commit.Description); match != nil {
+		if category, ok := conventionalTypes[strings.ToLower(match[1])]; ok {
+			return category
+		}
+	}
+
+	var keywordRules []KeywordRule = classification.Keywords
+	if len(keywordRules) == 0 {
+		keywordRules = defaultKeywordRules
+	}
+	var words []string = wordPattern.FindAllString(strings.ToLower(commit.Description), -1)
+	for _, rule := range keywordRules {
+		for _, word := range rule.Words {
+			if contains(words, strings.ToLower(word)) {
+				return rule.Category
+			}
+		}
+	}
+
+	var pathRules []PathRule = classification.Paths
+	if len(pathRules) == 0 {
+		pathRules = defaultPathRules
+	}
+	if len(commit.Files) > 0 {
+		for _, rule := range pathRules {
+			if allFilesMatch(commit.Files, rule.Patterns) {
+				return rule.Category
+			}
+		}
+	}
+
+	if len(commit.Files) > 0 {
+		return categoryFeature
+	}
+	return categoryOther
+}
+
+func allFilesMatch(files []FileChange, patterns []string) bool {
+	for _, file := range files {
+		if !matchAny(patterns, file.Path) {
+			return false
+		}
+	}
+	return true
+}
+
+func ClassifyCommits(commits []GitCommit, classification Classification) []GitCommit {
+	for i := range commits {
+		commits[i].Category = ClassifyCommit(commits[i], classification)
+	}
+	return commits
+}
+
+// CountOverallCommitByCategory is CountOverallCommit broken down by
+// category: category -> domain -> count.
+func CountOverallCommitByCategory(gitCommits []GitCommit, result map[string]map[string]int,
+	beginDate time.Time, endDate time.Time) {
+	for _, commit := range gitCommits {
+		if result[commit.Category] == nil {
+			result[commit.Category] = make(map[string]int)
+		}
+		CountOverallCommit([]GitCommit{commit}, result[commit.Category], beginDate, endDate)
+	}
+}
+
+// CreateCategoryOutputFile writes result.csv broken down by category as
+// contributor,repo,category,count rows.
+func CreateCategoryOutputFile(setting Setting, result map[string]map[string]map[string]int) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Contributor", "Code Repo", "Category", "Count"})
+	for _, contributor := range setting.Contributors {
+		for _, column := range ReportColumns(setting) {
+			var categories []string
+			for category := range result[contributor.Name][column] {
+				categories = append(categories, category)
+			}
+			sort.Strings(categories)
+
+			for _, category := range categories {
+				writer.Write([]string{contributor.Name, column, category,
+					strconv.Itoa(result[contributor.Name][column][category])})
+			}
+		}
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/result_categories.csv", buffer.Bytes(), 0644)
+}
+
+// CreateCategoryTotalOutputFile writes total_count.csv broken down by
+// category as category,domain,count rows.
+func CreateCategoryTotalOutputFile(result map[string]map[string]int) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var categories []string
+	for category := range result {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		for _, domain := range sortedByCount(result[category]) {
+			writer.Write([]string{category, domain, strconv.Itoa(result[category][domain])})
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/total_count_categories.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyCommit_Conventional(t *testing.T) {
+	assert.Equal(t, "feature", ClassifyCommit(GitCommit{Description: "feat(director): add ppc64le support"}, Classification{}))
+	assert.Equal(t, "bugfix", ClassifyCommit(GitCommit{Description: "fix!: drop broken flag"}, Classification{}))
+	assert.Equal(t, "docs", ClassifyCommit(GitCommit{Description: "docs: explain key_name"}, Classification{}))
+	assert.Equal(t, "chore", ClassifyCommit(GitCommit{Description: "ci: run on concourse"}, Classification{}))
+}
+
+func TestClassifyCommit_Keywords(t *testing.T) {
+	assert.Equal(t, "bugfix", ClassifyCommit(GitCommit{Description: "Fixed race in agent client"}, Classification{}))
+	assert.Equal(t, "chore", ClassifyCommit(GitCommit{Description: "Merge pull request #17 from hashmap/power-builder"}, Classification{}))
+	assert.Equal(t, "other", ClassifyCommit(GitCommit{Description: "Prefix matters"}, Classification{}))
+
+	var classification Classification = Classification{Keywords: []KeywordRule{{Category: "security", Words: []string{"CVE"}}}}
+	assert.Equal(t, "security", ClassifyCommit(GitCommit{Description: "Patch cve-2015-1234"}, classification))
+	assert.Equal(t, "other", ClassifyCommit(GitCommit{Description: "Fixed race in agent client"}, classification))
+}
+
+func TestClassifyCommit_Paths(t *testing.T) {
+	var docs GitCommit = GitCommit{Description: "Update", Files: []FileChange{{Path: "README.md"}, {Path: "docs/a.html"}}}
+	var tests GitCommit = GitCommit{Description: "Cover key_name", Files: []FileChange{{Path: "src/bosh-director/spec/unit/a_spec.rb"}}}
+	var mixed GitCommit = GitCommit{Description: "Add key_name", Files: []FileChange{{Path: "README.md"}, {Path: "lib/a.rb"}}}
+
+	assert.Equal(t, "docs", ClassifyCommit(docs, Classification{}))
+	assert.Equal(t, "test", ClassifyCommit(tests, Classification{}))
+	assert.Equal(t, "feature", ClassifyCommit(mixed, Classification{}))
+}
+
+var test_classification_data = `
+---
+classification:
+  keywords:
+  - category: bugfix
+    words: [regression]
+  paths:
+  - category: test
+    patterns: ["**/*_test.go"]
+`
+
+func TestEnrichCommits_Classification(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_classification_data))
+	assert.Nil(t, err)
+
+	var commits []GitCommit = enrichCommits(setting, []GitCommit{
+		{Description: "Regression in parser"},
+		{Description: "Cover parser", Files: []FileChange{{Path: "src/parser_test.go"}}},
+		{Description: "Cover parser", Files: []FileChange{{Path: "docs/parser.md"}}},
+	})
+	assert.Equal(t, "bugfix", commits[0].Category)
+	assert.Equal(t, "test", commits[1].Category)
+	assert.Equal(t, "feature", commits[2].Category)
+}
+
+func TestCountOverallCommitByCategory(t *testing.T) {
+	var commits []GitCommit = []GitCommit{
+		{AuthorDomain: "sap.com", Date: getDate("2015-10-01"), Category: "bugfix"},
+		{AuthorDomain: "sap.com", CoAuthorDomain: "emc.com", Date: getDate("2015-10-01"), Category: "feature"},
+		{AuthorDomain: "sap.com", Date: getDate("2014-10-01"), Category: "feature"},
+	}
+	var result map[string]map[string]int = make(map[string]map[string]int)
+
+	CountOverallCommitByCategory(commits, result, getDate("2015-05-31"), getDate("2016-01-01"))
+	assert.Equal(t, 1, result["bugfix"]["sap.com"])
+	assert.Equal(t, 1, result["feature"]["emc.com"])
+	assert.Equal(t, 2, result["feature"]["TOTAL"])
+}
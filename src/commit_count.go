@@ -3,6 +3,7 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -19,15 +20,49 @@ import (
 type Repository struct {
 	Name string
 	Url  string
+	// Existing clone or bare repository, read in place without fetching.
+	Path string
+	Refs RefSelection
+	Tags []string
+	// Path globs; when set only commits touching matching files count.
+	Include  []string
+	Exclude  []string
+	Projects []Project
 }
 
 type Contributor struct {
 	Name string
+	// Code hosting handle such as @victorfong, used for CODEOWNERS
+	Handle string
+	// Optional team, rolled up in the working-pattern heatmap
+	Team string
 }
 
 type Setting struct {
-	Repositories []Repository
-	Contributors []Contributor
+	Repositories   []Repository
+	Contributors   []Contributor
+	Organizations  []Organization
+	References     []ReferenceExtractor
+	JiraProjects   []string `yaml:"jira_projects"`
+	Classification Classification
+	Groups         []Group
+	Discovery      []DiscoverySource
+	Windows        []Window
+	Concentration  Concentration
+	Lifecycle      Lifecycle
+	Ownership      Ownership
+	Blame          Blame
+	Survival       Survival
+	Diff           Diff
+	Targets        []Target
+	Policies       []Policy
+	Heatmap        Heatmap
+	// IANA name of the timezone for days, windows and quarters, e.g.
+	// "America/New_York". By default each commit's own offset is used.
+	Timezone string
+	// Default ref selection for repositories without their own refs and
+	// for the repos.txt overall count.
+	Refs RefSelection
 }
 
 func UnmarshalYaml(data []byte) (Setting, error) {
@@ -38,6 +73,42 @@ func UnmarshalYaml(data []byte) (Setting, error) {
 		return Setting{}, err
 	}
 
+	extractors, err := t.referenceExtractors()
+	if err != nil {
+		return Setting{}, err
+	}
+	if _, err := compileExtractors(extractors); err != nil {
+		return Setting{}, err
+	}
+
+	if len(t.Windows) == 0 {
+		t.Windows = defaultWindows
+	}
+	if err := validateWindows(t.Windows); err != nil {
+		return Setting{}, err
+	}
+	if _, err := t.location(); err != nil {
+		return Setting{}, err
+	}
+	if err := validateTargets(t); err != nil {
+		return Setting{}, err
+	}
+	if err := validatePolicies(t); err != nil {
+		return Setting{}, err
+	}
+	if err := validateWorkingHours(t.workingHours()); err != nil {
+		return Setting{}, err
+	}
+
+	if len(t.Refs) == 0 {
+		t.Refs = defaultRefSelection
+	}
+	for i := range t.Repositories {
+		if len(t.Repositories[i].Refs) == 0 {
+			t.Repositories[i].Refs = t.Refs
+		}
+	}
+
 	return t, nil
 }
 
@@ -57,6 +128,14 @@ func ReadSettingFile(file_path string) (Setting, error) {
 }
 
 func fetchSource(repo Repository) error {
+	if path, ok := LocalPath(repo); ok {
+		if !dirExists(path) {
+			return fmt.Errorf("local repository %s not found at %s", repo.Name, path)
+		}
+		fmt.Printf("Using local repository %s (%s)\n", repo.Name, path)
+		return nil
+	}
+
 	fmt.Printf("Fetching %s (%s)\n", repo.Name, repo.Url)
 
 	var cmd *exec.Cmd = exec.Command(
@@ -68,6 +147,173 @@ func fetchSource(repo Repository) error {
 	return err
 }
 
+// File lists are needed for path filters, so the log always carries --numstat.
+var logFormatArgs []string = []string{"--numstat"}
+
+type RunOptions struct {
+	UseCache bool
+	// Never fetch; only use clones, caches and log files already on disk.
+	Offline bool
+}
+
+// LocalPath returns the on-disk location of repositories configured with
+// path: or a file:// url. Those are never cloned into work/.
+func LocalPath(repo Repository) (string, bool) {
+	if repo.Path != "" {
+		return repo.Path, true
+	}
+	if strings.HasPrefix(repo.Url, "file://") {
+		return strings.TrimPrefix(repo.Url, "file://"), true
+	}
+	return "", false
+}
+
+func repoDir(repo Repository) string {
+	if path, ok := LocalPath(repo); ok {
+		return path
+	}
+	return "work/" + repo.Name
+}
+
+func logFilePath(repoName string) string {
+	return "work/" + repoName + "_log.txt"
+}
+
+// repoRefs lists every ref of the clone together with its SHA. It changes
+// whenever a fetch moves a branch or tag, which makes it the parse cache key.
+func repoRefs(dir string) (string, error) {
+	return gitOutput(dir, "show-ref", "--head")
+}
+
+func gitOutput(dir string, args ...string) (string, error) {
+	var cmd *exec.Cmd = exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.Output()
+	return string(out), err
+}
+
+func generateLog(repo Repository, args []string) error {
+	fmt.Printf("Generating log file for %s\n", repo.Name)
+
+	outFile, err := os.Create(logFilePath(repo.Name))
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	var cmd *exec.Cmd = exec.Command("git", append([]string{"log"}, args...)...)
+	cmd.Dir = repoDir(repo)
+	cmd.Stdout = outFile
+	return cmd.Run()
+}
+
+func parseLogFile(repoName string) []GitCommit {
+	inFile, err := os.Open(logFilePath(repoName))
+	if err != nil {
+		panic(err)
+	}
+	defer inFile.Close()
+
+	scanner := bufio.NewScanner(inFile)
+	return ReadCommit(scanner, repoName)
+}
+
+// loadCommits returns the parsed history of an already fetched repository,
+// reusing work/cache when none of its refs moved since the last run.
+func loadCommits(repo Repository, options RunOptions) []GitCommit {
+	refs, err := repoRefs(repoDir(repo))
+	if err != nil {
+		panic(err)
+	}
+	var revisions []string = LogArgs(repo.Refs, RefNames(refs))
+	if len(revisions) == 0 {
+		fmt.Printf("No refs selected for %s\n", repo.Name)
+		return nil
+	}
+	var logArgs []string = append(append([]string{}, logFormatArgs...), revisions...)
+	var key string = CacheKey(refs, logArgs)
+
+	if options.UseCache {
+		if commits, ok := ReadCache(cacheDir, repo.Name, key); ok {
+			fmt.Printf("Using cached commits for %s\n", repo.Name)
+			return commits
+		}
+	}
+
+	if err := generateLog(repo, logArgs); err != nil {
+		panic(err)
+	}
+	var commits []GitCommit = parseLogFile(repo.Name)
+
+	if err := WriteCache(cacheDir, repo.Name, key, commits); err != nil {
+		fmt.Printf("Unable to write cache for %s: %s\n", repo.Name, err)
+	}
+	return commits
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// loadOfflineCommits uses the first local source available: the clone
+// itself, the last parse cache entry, then work/<name>_log.txt.
+func loadOfflineCommits(repo Repository, options RunOptions) []GitCommit {
+	if dirExists(repoDir(repo)) {
+		return loadCommits(repo, options)
+	}
+
+	if commits, ok := ReadAnyCache(cacheDir, repo.Name); ok {
+		fmt.Printf("Using last cached commits for %s\n", repo.Name)
+		return commits
+	}
+
+	if fileExists(logFilePath(repo.Name)) {
+		fmt.Printf("Using existing log file for %s\n", repo.Name)
+		return parseLogFile(repo.Name)
+	}
+
+	fmt.Printf("WARNING: no local data for %s, it is left out of the results\n", repo.Name)
+	return nil
+}
+
+// historyOf fetches a repository and returns its parsed commits. Offline,
+// nothing is fetched and only data already on disk is used.
+func historyOf(repo Repository, options RunOptions) []GitCommit {
+	if options.Offline {
+		return loadOfflineCommits(repo, options)
+	}
+
+	fetch_error := fetchSource(repo)
+	if fetch_error != nil {
+		fmt.Printf("ERROR FETCH: %s\n", repo.Name)
+		panic(fetch_error)
+	}
+
+	return loadCommits(repo, options)
+}
+
+// enrichCommits runs the configurable stages that annotate parsed commits.
+// They run after the parse cache, so editing setting.yml takes effect
+// without re-parsing.
+func enrichCommits(setting Setting, commits []GitCommit) []GitCommit {
+	extractors, err := setting.referenceExtractors()
+	if err != nil {
+		panic(err)
+	}
+	commits, err = AttachReferences(commits, extractors)
+	if err != nil {
+		panic(err)
+	}
+	return ClassifyCommits(commits, setting.Classification)
+}
+
 type GitCommit struct {
 	Author         string
 	Date           time.Time
@@ -76,6 +322,54 @@ type GitCommit struct {
 	Repo           string
 	AuthorDomain   string
 	CoAuthorDomain string
+	Files          []FileChange
+	// Set on "Merge pull request #17 from hashmap/power-builder" commits
+	PullRequest       int
+	PullRequestOwner  string
+	PullRequestBranch string
+	// Filled in by enrichCommits, not stored in the parse cache
+	References []Reference
+	Category   string
+}
+
+// FileChange is one line of git log --numstat output. Binary files are
+// reported with zero added and deleted lines.
+type FileChange struct {
+	Path    string
+	Added   int
+	Deleted int
+}
+
+// ParseNumstat reads "added<TAB>deleted<TAB>path" lines. Renames are recorded
+// under their new path.
+func ParseNumstat(line string) (FileChange, bool) {
+	elements := strings.Split(line, "\t")
+	if len(elements) != 3 || strings.HasPrefix(line, " ") {
+		return FileChange{}, false
+	}
+
+	added, addErr := strconv.Atoi(elements[0])
+	deleted, delErr := strconv.Atoi(elements[1])
+	if (addErr != nil && elements[0] != "-") || (delErr != nil && elements[1] != "-") {
+		return FileChange{}, false
+	}
+
+	return FileChange{Path: renamedPath(elements[2]), Added: added, Deleted: deleted}, true
+}
+
+func renamedPath(path string) string {
+	if begin := strings.Index(path, "{"); begin >= 0 {
+		end := strings.Index(path, "}")
+		arrow := strings.Index(path, " => ")
+		if end > arrow && arrow > begin {
+			var result string = path[:begin] + path[arrow+4:end] + path[end+1:]
+			return strings.Replace(result, "//", "/", -1)
+		}
+	}
+	if arrow := strings.Index(path, " => "); arrow >= 0 {
+		return path[arrow+4:]
+	}
+	return path
 }
 
 func GetCoAuthor(line string) string {
@@ -107,10 +401,11 @@ func GetFirstWord(line string) string {
 	return elements[0]
 }
 
+// parseDate keeps the exact author time together with its original offset,
+// e.g. "Date:   Sun Oct 18 17:44:34 2015 -0400".
 func parseDate(line string) time.Time {
-	var elements []string = strings.Split(line, " ")
-	var dateString string = elements[7] + "-" + elements[4] + "-" + elements[5]
-	result, err := time.Parse("2006-Jan-2", dateString)
+	var dateString string = strings.Join(strings.Fields(line)[1:], " ")
+	result, err := time.Parse("Mon Jan 2 15:04:05 2006 -0700", dateString)
 	if err != nil {
 		fmt.Printf("Error parsing line: %s\n", line)
 		panic(err)
@@ -137,6 +432,7 @@ func ReadCommit(scanner *bufio.Scanner, repo string) []GitCommit {
 			var authorDomain string
 			var coauthorDomain string
 			var date time.Time
+			var files []FileChange
 
 			isTwoAuthorPattern, author, coauthor := IsTwoAuthorPattern(line)
 			if !isTwoAuthorPattern {
@@ -157,6 +453,11 @@ func ReadCommit(scanner *bufio.Scanner, repo string) []GitCommit {
 
 				firstWord = GetFirstWord(line)
 
+				if file, ok := ParseNumstat(line); ok {
+					files = append(files, file)
+					continue
+				}
+
 				if firstWord == "Signed-off-by:" {
 					coauthor = GetCoAuthor(line)
 					coauthorDomain = GetEmailDomain(line)
@@ -171,6 +472,7 @@ func ReadCommit(scanner *bufio.Scanner, repo string) []GitCommit {
 			}
 
 			description = strings.Trim(description, " ")
+			pullRequest, pullRequestOwner, pullRequestBranch, _ := ParsePullRequestMerge(description)
 
 			commit := GitCommit{
 				Author:         author,
@@ -180,9 +482,18 @@ func ReadCommit(scanner *bufio.Scanner, repo string) []GitCommit {
 				Repo:           repo,
 				AuthorDomain:   authorDomain,
 				CoAuthorDomain: coauthorDomain,
+				Files:          files,
+
+				PullRequest:       pullRequest,
+				PullRequestOwner:  pullRequestOwner,
+				PullRequestBranch: pullRequestBranch,
 			}
 			result = append(result, commit)
 
+		} else if file, ok := ParseNumstat(line); ok && len(result) > 0 {
+			// --numstat lines that follow a Signed-off-by trailer
+			var last *GitCommit = &result[len(result)-1]
+			last.Files = append(last.Files, file)
 		}
 	}
 
@@ -239,24 +550,26 @@ func CountCommits(file_path string, repo_name string, setting Setting, log_buffe
 func CreateOutputFile(setting Setting, result map[string]map[string]int) {
 	var buffer bytes.Buffer
 
+	var columns []string = ReportColumns(setting)
+
 	buffer.WriteString(",")
-	for i, repo := range setting.Repositories {
+	for i, column := range columns {
 		if i != 0 {
 			buffer.WriteString(",")
 		}
-		buffer.WriteString(repo.Name)
+		buffer.WriteString(column)
 	}
 	buffer.WriteString("\n")
 
 	for _, contributor := range setting.Contributors {
 		buffer.WriteString(contributor.Name)
 		buffer.WriteString(",")
-		for j, repo := range setting.Repositories {
+		for j, column := range columns {
 			if j != 0 {
 				buffer.WriteString(",")
 			}
-			fmt.Printf("%s at repo %s = %d\n", contributor.Name, repo.Name, result[contributor.Name][repo.Name])
-			buffer.WriteString(strconv.Itoa(result[contributor.Name][repo.Name]))
+			fmt.Printf("%s at repo %s = %d\n", contributor.Name, column, result[contributor.Name][column])
+			buffer.WriteString(strconv.Itoa(result[contributor.Name][column]))
 		}
 		buffer.WriteString("\n")
 	}
@@ -301,48 +614,137 @@ func CreateLogOutputFile(setting Setting, log_result map[string][]GitCommit) {
 }
 
 func main() {
+	noCache := flag.Bool("no-cache", false, "ignore work/cache and re-parse every repository")
+	offline := flag.Bool("offline", false, "do not fetch, only use data already in work/")
+	gate := flag.Bool("gate", false, "exit with status 1 when a policy is violated")
+	flag.Parse()
+
+	var options RunOptions = RunOptions{UseCache: !*noCache, Offline: *offline}
+
+	if flag.Arg(0) == "parse" {
+		if err := RunParse(flag.Args()[1:], os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Printf("Reading Setting File: setting.yml\n")
 	setting, err := ReadSettingFile("setting.yml")
 	if err != nil {
 		panic(err)
 	}
 
+	reportingLocation, _ = setting.location()
+
+	if flag.Arg(0) == "discover" {
+		if err := RunDiscover(setting); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Local repositories are never cloned, so fetch-source may not create it
+	if err := os.MkdirAll("work", 0755); err != nil {
+		panic(err)
+	}
+
+	if flag.Arg(0) == "blame" {
+		if flag.NArg() > 1 {
+			setting.Blame.Ref = flag.Arg(1)
+		}
+		RunBlame(setting, options)
+		return
+	}
+
+	if flag.Arg(0) == "survival" {
+		RunSurvival(setting, options, time.Now())
+		return
+	}
+
+	if flag.Arg(0) == "trend" {
+		CreateTrendOutputFile(ReadRuns(storePath))
+		return
+	}
+
+	if flag.Arg(0) == "diff" {
+		if err := RunDiff(setting, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var count_result map[string]map[string]int = make(map[string]map[string]int)
+	var group_result map[string]map[string]int = make(map[string]map[string]int)
+	var category_result map[string]map[string]map[string]int = make(map[string]map[string]map[string]int)
 	var log_result map[string][]GitCommit = make(map[string][]GitCommit)
+	var history map[string][]GitCommit = make(map[string][]GitCommit)
+	// The same commits before path filters. Merges have no file list, so
+	// pull requests are only found here.
+	var unfiltered map[string][]GitCommit = make(map[string][]GitCommit)
+	// Unfiltered commits of the matrix repositories, reused by the overall count
+	var parsed map[string][]GitCommit = make(map[string][]GitCommit)
 
 	for _, contributor := range setting.Contributors {
 		count_result[contributor.Name] = make(map[string]int)
+		group_result[contributor.Name] = make(map[string]int)
+		category_result[contributor.Name] = make(map[string]map[string]int)
 		log_result[contributor.Name] = make([]GitCommit, 0)
 	}
 
+	var configured map[string]bool = make(map[string]bool)
+	for _, repo := range setting.Repositories {
+		configured[repo.Name] = true
+	}
+	var repoGroups map[string][]string = GroupsByRepoName(setting)
+
 	fmt.Printf("Fetching History\n")
 	var wg sync.WaitGroup
+	var mutex sync.Mutex
 	// var log_buffer bytes.Buffer
-	for _, repo := range setting.Repositories {
+	for _, repo := range MatrixRepositories(setting) {
 		wg.Add(1)
+		fetchSlots <- true
 		go func(repo1 Repository) {
 			defer wg.Done()
-
-			fetch_error := fetchSource(repo1)
-			if fetch_error != nil {
-				panic(fetch_error)
-			}
-
-			var file_path string = "work/" + repo1.Name + "_log.txt"
-			inFile, err := os.Open(file_path)
-			if err != nil {
-				panic(err)
-			}
-			defer inFile.Close()
-
-			scanner := bufio.NewScanner(inFile)
-			var commits []GitCommit = ReadCommit(scanner, repo1.Name)
+			defer func() { <-fetchSlots }()
+			var gitCommits []GitCommit = enrichCommits(setting, historyOf(repo1, options))
+
+			// Every report built on history only sees commits within the
+			// repository's include/exclude paths
+			var commits []GitCommit = FilterCommitsByPath(gitCommits, repo1.Include, repo1.Exclude)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			history[repo1.Name] = commits
+			unfiltered[repo1.Name] = gitCommits
+			parsed[historyKey(repo1)] = gitCommits
 			for _, commit := range commits {
-				// When Author and CoAuthor are both EMC, only counts as 1
 				isEmcCommit, contributorName := IsEmcCommit(commit, setting.Contributors)
 				if isEmcCommit {
-					count_result[contributorName][repo1.Name] += 1
-					log_result[contributorName] = append(log_result[contributorName], commit)
+					for _, groupName := range repoGroups[repo1.Name] {
+						group_result[contributorName][groupName] += 1
+					}
+				}
+			}
+
+			if !configured[repo1.Name] {
+				return
+			}
+			for projectName, projectCommits := range SplitProjects(repo1, commits) {
+				for _, commit := range projectCommits {
+					// When Author and CoAuthor are both EMC, only counts as 1
+					isEmcCommit, contributorName := IsEmcCommit(commit, setting.Contributors)
+					if isEmcCommit {
+						count_result[contributorName][projectName] += 1
+						if category_result[contributorName][projectName] == nil {
+							category_result[contributorName][projectName] = make(map[string]int)
+						}
+						category_result[contributorName][projectName][commit.Category] += 1
+						log_result[contributorName] = append(log_result[contributorName], commit)
+					}
 				}
 			}
 
@@ -357,9 +759,40 @@ func main() {
 	wg.Wait()
 	CreateLogOutputFile(setting, log_result)
 	CreateOutputFile(setting, count_result)
+	if len(setting.Groups) > 0 {
+		CreateGroupOutputFile(setting, group_result)
+	}
+	CreateCategoryOutputFile(setting, category_result)
+	CreatePullRequestOutputFile(CountPullRequests(setting, unfiltered))
+	CreateReferenceOutputFile(setting, CountReferences(setting, history))
+	CreateLeaderboardOutputFile(setting, history)
+	CreateBusFactorOutputFile(setting, history)
+	CreateLifecycleOutputFile(setting, history, time.Now())
+	CreatePairingOutputFiles(setting, history)
+	CreateOwnershipOutputFile(setting, history, time.Now())
+	CreateHeatmapOutputFiles(setting, history)
+	if len(setting.Targets) > 0 {
+		CreateTargetOutputFile(setting, history, unfiltered, time.Now())
+	}
+	var violations []Violation = EvaluatePolicies(setting, history, time.Now())
+	CreateViolationOutputFile(violations)
+
+	FetchOverallCount(setting, options, parsed)
 
-	FetchOverallCount()
+	snapshot, err := SaveSnapshot("work", snapshotDir, time.Now())
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Saved snapshot %s\n", snapshot)
 
+	if err := RecordRun(setting, history, time.Now()); err != nil {
+		panic(err)
+	}
+	CreateTrendOutputFile(ReadRuns(storePath))
+
+	if len(violations) > 0 && *gate {
+		os.Exit(1)
+	}
 }
 
 func getRepoName(url string) string {
@@ -367,24 +800,12 @@ func getRepoName(url string) string {
 	return strings.Split(elements[len(elements)-1], ".")[0]
 }
 
-func getScanner(repoName string) *bufio.Scanner {
-	var file_path string = "work/" + repoName + "_log.txt"
-	inFile, err := os.Open(file_path)
-	if err != nil {
-		panic(err)
-	}
-	// defer inFile.Close()
-
-	scanner := bufio.NewScanner(inFile)
-	return scanner
-}
-
 func CountOverallCommit(gitCommits []GitCommit, result map[string]int,
 	beginDate time.Time, endDate time.Time) {
 	for _, commit := range gitCommits {
 
 
-		if commit.Date.After(beginDate) && commit.Date.Before(endDate){
+		if dayOf(commit.Date).After(beginDate) && dayOf(commit.Date).Before(endDate){
 			if commit.AuthorDomain != "" {
 				result[commit.AuthorDomain]++
 				result["TOTAL"]++
@@ -433,37 +854,62 @@ func getDate(dateString string) time.Time {
 	return result
 }
 
-func FetchOverallCount() {
-	var repoMap map[string]string = getRepos("repos.txt")
-	var result map[string]int = make(map[string]int)
+// fetchSlots bounds how many repositories are fetched and parsed at a time.
+var fetchSlots chan bool = make(chan bool, 30)
 
-	var beginDate time.Time = getDate("2015-05-31")
-	var endDate time.Time = getDate("2016-01-01")
+// historyKey identifies the history historyOf returns for a repository, so
+// a repository of both the matrix and repos.txt is parsed once.
+func historyKey(repo Repository) string {
+	return strings.Join([]string{repo.Name, normalizeUrl(repo.Url), repo.Path, strings.Join(repo.Refs, ",")}, "\x00")
+}
+
+// FetchOverallCount counts every repository of repos.txt and of imported
+// group lists. parsed holds the commits already parsed by historyKey.
+func FetchOverallCount(setting Setting, options RunOptions, parsed map[string][]GitCommit) {
+	var repoMap map[string]string = OverallRepositories(setting, "repos.txt")
+	var result map[string]int = make(map[string]int)
+	var group_total map[string]map[string]int = make(map[string]map[string]int)
+	var category_total map[string]map[string]int = make(map[string]map[string]int)
+	var urlGroups map[string][]string = GroupsByUrl(setting)
+	for _, group := range setting.Groups {
+		group_total[group.Name] = make(map[string]int)
+	}
 
-	concurrency := 30
-	sem := make(chan bool, concurrency)
+	// total_count.csv covers the first window
+	beginDate, endDate := setting.Windows[0].Dates()
+	var share_result map[string]map[string]map[string]int = make(map[string]map[string]map[string]int)
+	for _, window := range setting.Windows {
+		share_result[window.Name] = make(map[string]map[string]int)
+	}
 
 	var wg1 sync.WaitGroup
+	var mutex sync.Mutex
+	var countOverall = func(gitCommits []GitCommit, repo1 Repository) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		CountOverallCommit(gitCommits, result, beginDate, endDate)
+		CountOverallCommitByCategory(gitCommits, category_total, beginDate, endDate)
+		for _, window := range setting.Windows {
+			share_result[window.Name][repo1.Name] = CountOrganizationShare(setting, gitCommits, window)
+		}
+		for _, groupName := range urlGroups[normalizeUrl(repo1.Url)] {
+			CountOverallCommit(gitCommits, group_total[groupName], beginDate, endDate)
+		}
+		fmt.Printf("COUNT = %d, TOTAL = %d (%s)\n", len(result), result["TOTAL"], repo1.Name)
+	}
 	for repoName, url := range repoMap {
 
-		var repo Repository = Repository{Name: repoName, Url: url}
+		var repo Repository = Repository{Name: repoName, Url: url, Refs: setting.Refs}
+		if gitCommits, ok := parsed[historyKey(repo)]; ok {
+			countOverall(gitCommits, repo)
+			continue
+		}
 		wg1.Add(1)
-		sem <- true
+		fetchSlots <- true
 		go func(repo1 Repository) {
 			defer wg1.Done()
-			defer func() { <-sem }()
-			fetch_error := fetchSource(repo1)
-
-			if fetch_error != nil {
-				fmt.Printf("ERROR FETCH: %s\n", repo.Name)
-				panic(fetch_error)
-			}
-
-			var scanner *bufio.Scanner = getScanner(repo1.Name)
-			var gitCommits []GitCommit = ReadCommit(scanner, repo1.Name)
-
-			CountOverallCommit(gitCommits, result, beginDate, endDate)
-			fmt.Printf("COUNT = %d, TOTAL = %d (%s)\n", len(result), result["TOTAL"], repo1.Name)
+			defer func() { <-fetchSlots }()
+			countOverall(enrichCommits(setting, historyOf(repo1, options)), repo1)
 		}(repo)
 	}
 
@@ -476,6 +922,11 @@ func FetchOverallCount() {
 	}
 
 	CreateTotalCountOutputFile(result)
+	CreateCategoryTotalOutputFile(category_total)
+	CreateShareOutputFile(setting, share_result)
+	if len(setting.Groups) > 0 {
+		CreateGroupTotalOutputFile(setting, group_total)
+	}
 }
 
 func CreateTotalCountOutputFile(result map[string]int) {
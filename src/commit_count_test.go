@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -80,12 +81,12 @@ func TestReadCommit(t *testing.T) {
 	assert.Equal(t, "Merge branch 'master' into hotfix-postgres", gitCommits[0].Description)
 	assert.Equal(t, "repo1", gitCommits[0].Repo)
 	var date time.Time = getDate("2015-10-15")
-	assert.True(t, date.Equal(gitCommits[0].Date))
+	assert.True(t, date.Equal(dayOf(gitCommits[0].Date)))
 
 	assert.Equal(t, "Devin Fallak", gitCommits[1].Author)
 	assert.Equal(t, "Update README.md", gitCommits[1].Description)
 	var date2 time.Time = getDate("2015-10-14")
-	assert.True(t, date2.Equal(gitCommits[1].Date))
+	assert.True(t, date2.Equal(dayOf(gitCommits[1].Date)))
 
 }
 
@@ -157,8 +158,12 @@ func TestGetTestDomain(t *testing.T) {
 func TestParseDate(t *testing.T) {
 	var testString string = "Date:   Sun Oct 18 17:44:34 2015 -0400"
 	var result time.Time = parseDate(testString)
-	expectedResult, _ := time.Parse("01-02-2006", "10-18-2015")
+	var expectedResult time.Time = time.Date(2015, 10, 18, 21, 44, 34, 0, time.UTC)
 	assert.True(t, expectedResult.Equal(result))
+
+	_, offset := result.Zone()
+	assert.Equal(t, -4*60*60, offset)
+	assert.Equal(t, "2015-10-18T17:44:34-04:00", result.Format(time.RFC3339))
 }
 
 func TestGetDate(t *testing.T) {
@@ -290,3 +295,50 @@ func TestGetRepos(t *testing.T) {
 	assert.Equal(t, "https://github.com/cloudfoundry/api-docs.git", result["api-docs"])
 	assert.Equal(t, "https://github.com/cloudfoundry/binary-builder.git", result["binary-builder"])
 }
+
+var test_local_data = `
+---
+repositories:
+- name: Bosh
+  path: /srv/git/bosh.git
+- name: UAA
+  url: file:///srv/git/uaa
+- name: CPI
+  url: https://github.com/cloudfoundry-incubator/bosh-aws-cpi-release.git
+`
+
+func TestLocalPath(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_local_data))
+	assert.Equal(t, nil, err)
+
+	path, ok := LocalPath(setting.Repositories[0])
+	assert.True(t, ok)
+	assert.Equal(t, "/srv/git/bosh.git", path)
+	assert.Equal(t, "/srv/git/bosh.git", repoDir(setting.Repositories[0]))
+
+	path, ok = LocalPath(setting.Repositories[1])
+	assert.True(t, ok)
+	assert.Equal(t, "/srv/git/uaa", path)
+
+	_, ok = LocalPath(setting.Repositories[2])
+	assert.False(t, ok)
+	assert.Equal(t, "work/CPI", repoDir(setting.Repositories[2]))
+}
+
+func TestFetchSource_MissingLocal(t *testing.T) {
+	var err error = fetchSource(Repository{Name: "Bosh", Path: "/does/not/exist"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "/does/not/exist")
+
+	assert.Nil(t, fetchSource(Repository{Name: "Bosh", Path: os.TempDir()}))
+}
+
+func TestHistoryKey(t *testing.T) {
+	var imported Repository = Repository{Name: "Bosh", Url: "https://github.com/cloudfoundry/bosh.git", Refs: defaultRefSelection}
+	assert.Equal(t, historyKey(imported),
+		historyKey(Repository{Name: "Bosh", Url: "https://github.com/cloudfoundry/bosh", Refs: defaultRefSelection}))
+	assert.NotEqual(t, historyKey(imported),
+		historyKey(Repository{Name: "Bosh", Url: "https://github.com/cloudfoundry/bosh.git", Refs: RefSelection{"default"}}))
+	assert.NotEqual(t, historyKey(imported),
+		historyKey(Repository{Name: "Bosh", Path: "/srv/git/bosh.git", Refs: defaultRefSelection}))
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DiscoverySource is one organization to list repositories from. Every
+// source writes its repositories to Output, a repos.txt style list that
+// getRepos and group imports consume.
+type DiscoverySource struct {
+	Provider        string
+	BaseUrl         string `yaml:"base_url"`
+	Organization    string
+	Token           string
+	IncludeArchived bool `yaml:"include_archived"`
+	IncludeForks    bool `yaml:"include_forks"`
+	Topics          []string
+	NamePattern     string `yaml:"name_pattern"`
+	Output          string
+}
+
+type DiscoveredRepository struct {
+	Name     string
+	CloneUrl string
+	Archived bool
+	Fork     bool
+	Topics   []string
+}
+
+// RepositoryProvider lists every repository of an organization on a code
+// hosting service. Filtering is done by FilterDiscovered.
+type RepositoryProvider interface {
+	ListRepositories(source DiscoverySource) ([]DiscoveredRepository, error)
+}
+
+var repositoryProviders map[string]RepositoryProvider = map[string]RepositoryProvider{
+	"github": GitHubProvider{},
+	"gitlab": GitLabProvider{},
+	"gitea":  GiteaProvider{},
+}
+
+func (repo DiscoveredRepository) Repository() Repository {
+	return Repository{Name: repo.Name, Url: repo.CloneUrl}
+}
+
+func (source DiscoverySource) outputPath() string {
+	if source.Output == "" {
+		return "repos.txt"
+	}
+	return source.Output
+}
+
+// sourceToken prefers the token in setting.yml and falls back to the
+// provider's usual environment variable, e.g. GITHUB_TOKEN.
+func sourceToken(source DiscoverySource, envName string) string {
+	if source.Token != "" {
+		return source.Token
+	}
+	return os.Getenv(envName)
+}
+
+func FilterDiscovered(repos []DiscoveredRepository, source DiscoverySource) []DiscoveredRepository {
+	var result []DiscoveredRepository
+	for _, repo := range repos {
+		if repo.Archived && !source.IncludeArchived {
+			continue
+		}
+		if repo.Fork && !source.IncludeForks {
+			continue
+		}
+		if source.NamePattern != "" {
+			if ok, _ := path.Match(source.NamePattern, repo.Name); !ok {
+				continue
+			}
+		}
+		if len(source.Topics) > 0 && !hasAnyTopic(repo, source.Topics) {
+			continue
+		}
+		result = append(result, repo)
+	}
+	return result
+}
+
+func hasAnyTopic(repo DiscoveredRepository, topics []string) bool {
+	for _, topic := range topics {
+		if contains(repo.Topics, topic) {
+			return true
+		}
+	}
+	return false
+}
+
+func DiscoverRepositories(source DiscoverySource) ([]Repository, error) {
+	provider, ok := repositoryProviders[source.Provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown discovery provider %q", source.Provider)
+	}
+
+	fmt.Printf("Discovering %s repositories of %s\n", source.Provider, source.Organization)
+	repos, err := provider.ListRepositories(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Repository
+	for _, repo := range FilterDiscovered(repos, source) {
+		result = append(result, repo.Repository())
+	}
+	return result, nil
+}
+
+func readUrlList(filepath string) []string {
+	var result []string
+
+	file, err := os.Open(filepath)
+	if err != nil {
+		return result
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if url := strings.TrimSpace(scanner.Text()); url != "" {
+			result = append(result, url)
+		}
+	}
+	return result
+}
+
+// WriteUrlList replaces the list with the given urls, sorted, and prints
+// which urls were added and removed compared to the previous list.
+func WriteUrlList(filepath string, urls []string) error {
+	var previous []string = readUrlList(filepath)
+	sort.Strings(urls)
+
+	for _, url := range urls {
+		if !contains(previous, url) {
+			fmt.Printf("+ %s\n", url)
+		}
+	}
+	for _, url := range previous {
+		if !contains(urls, url) {
+			fmt.Printf("- %s\n", url)
+		}
+	}
+
+	var buffer bytes.Buffer
+	for _, url := range urls {
+		buffer.WriteString(url)
+		buffer.WriteString("\n")
+	}
+	return ioutil.WriteFile(filepath, buffer.Bytes(), 0644)
+}
+
+// RunDiscover refreshes the output list of every discovery source. Sources
+// sharing an output file are merged into it.
+func RunDiscover(setting Setting) error {
+	var outputs []string
+	var urls map[string][]string = make(map[string][]string)
+
+	for _, source := range setting.Discovery {
+		repos, err := DiscoverRepositories(source)
+		if err != nil {
+			return err
+		}
+
+		var output string = source.outputPath()
+		if _, ok := urls[output]; !ok {
+			outputs = append(outputs, output)
+			urls[output] = []string{}
+		}
+		for _, repo := range repos {
+			if !contains(urls[output], repo.Url) {
+				urls[output] = append(urls[output], repo.Url)
+			}
+		}
+	}
+
+	for _, output := range outputs {
+		fmt.Printf("Writing %d repositories to %s\n", len(urls[output]), output)
+		if err := WriteUrlList(output, urls[output]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var httpClient *http.Client = &http.Client{Timeout: 30 * time.Second}
+
+// getJson decodes the JSON body of a GET request into target and returns
+// the response status code.
+func getJson(url string, headers map[string]string, target interface{}) (int, error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := httpClient.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return response.StatusCode, fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	return response.StatusCode, json.NewDecoder(response.Body).Decode(target)
+}
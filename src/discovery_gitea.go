@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const giteaPageSize = 50
+
+// GiteaProvider lists the repositories of a Gitea organization. Gitea is
+// usually self-hosted, so BaseUrl should be set; it defaults to gitea.com.
+type GiteaProvider struct{}
+
+type giteaRepository struct {
+	Name     string   `json:"name"`
+	CloneUrl string   `json:"clone_url"`
+	Archived bool     `json:"archived"`
+	Fork     bool     `json:"fork"`
+	Topics   []string `json:"topics"`
+}
+
+func (provider GiteaProvider) ListRepositories(source DiscoverySource) ([]DiscoveredRepository, error) {
+	var baseUrl string = strings.TrimSuffix(source.BaseUrl, "/")
+	if baseUrl == "" {
+		baseUrl = "https://gitea.com"
+	}
+
+	var headers map[string]string = map[string]string{}
+	if token := sourceToken(source, "GITEA_TOKEN"); token != "" {
+		headers["Authorization"] = "token " + token
+	}
+
+	var result []DiscoveredRepository
+	for page := 1; ; page++ {
+		var repos []giteaRepository
+		var requestUrl string = fmt.Sprintf("%s/api/v1/orgs/%s/repos?limit=%d&page=%d",
+			baseUrl, url.PathEscape(source.Organization), giteaPageSize, page)
+
+		if _, err := getJson(requestUrl, headers, &repos); err != nil {
+			return nil, err
+		}
+
+		for _, repo := range repos {
+			result = append(result, DiscoveredRepository{
+				Name:     repo.Name,
+				CloneUrl: repo.CloneUrl,
+				Archived: repo.Archived,
+				Fork:     repo.Fork,
+				Topics:   repo.Topics,
+			})
+		}
+		// Servers cap limit at their MAX_RESPONSE_ITEMS, which may be below
+		// giteaPageSize, so a short page is not necessarily the last one
+		if len(repos) == 0 {
+			break
+		}
+	}
+
+	return result, nil
+}
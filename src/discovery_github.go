@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const githubPageSize = 100
+
+// GitHubProvider lists repositories through the GitHub REST API. BaseUrl
+// defaults to api.github.com; GitHub Enterprise uses https://host/api/v3.
+type GitHubProvider struct{}
+
+type githubRepository struct {
+	Name     string   `json:"name"`
+	CloneUrl string   `json:"clone_url"`
+	Archived bool     `json:"archived"`
+	Fork     bool     `json:"fork"`
+	Topics   []string `json:"topics"`
+}
+
+func (provider GitHubProvider) ListRepositories(source DiscoverySource) ([]DiscoveredRepository, error) {
+	var baseUrl string = strings.TrimSuffix(source.BaseUrl, "/")
+	if baseUrl == "" {
+		baseUrl = "https://api.github.com"
+	}
+
+	var headers map[string]string = map[string]string{"Accept": "application/vnd.github+json"}
+	if token := sourceToken(source, "GITHUB_TOKEN"); token != "" {
+		headers["Authorization"] = "token " + token
+	}
+
+	// Organizations and users have separate endpoints
+	var result []DiscoveredRepository
+	for _, owner := range []string{"orgs", "users"} {
+		result = nil
+		var found bool = true
+
+		for page := 1; ; page++ {
+			var repos []githubRepository
+			var url string = fmt.Sprintf("%s/%s/%s/repos?type=all&per_page=%d&page=%d",
+				baseUrl, owner, source.Organization, githubPageSize, page)
+
+			status, err := getJson(url, headers, &repos)
+			if status == http.StatusNotFound {
+				found = false
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, repo := range repos {
+				result = append(result, DiscoveredRepository{
+					Name:     repo.Name,
+					CloneUrl: repo.CloneUrl,
+					Archived: repo.Archived,
+					Fork:     repo.Fork,
+					Topics:   repo.Topics,
+				})
+			}
+			if len(repos) < githubPageSize {
+				break
+			}
+		}
+
+		if found {
+			return result, nil
+		}
+	}
+
+	return nil, fmt.Errorf("github organization or user %s not found", source.Organization)
+}
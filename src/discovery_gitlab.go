@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const gitlabPageSize = 100
+
+// GitLabProvider lists the projects of a GitLab group, including every
+// subgroup. BaseUrl defaults to gitlab.com.
+type GitLabProvider struct{}
+
+type gitlabProject struct {
+	Path          string   `json:"path"`
+	HttpUrlToRepo string   `json:"http_url_to_repo"`
+	Archived      bool     `json:"archived"`
+	Topics        []string `json:"topics"`
+	TagList       []string `json:"tag_list"`
+	// Only present on forks
+	ForkedFromProject *struct{} `json:"forked_from_project"`
+}
+
+func (provider GitLabProvider) ListRepositories(source DiscoverySource) ([]DiscoveredRepository, error) {
+	var baseUrl string = strings.TrimSuffix(source.BaseUrl, "/")
+	if baseUrl == "" {
+		baseUrl = "https://gitlab.com"
+	}
+
+	var headers map[string]string = map[string]string{}
+	if token := sourceToken(source, "GITLAB_TOKEN"); token != "" {
+		headers["PRIVATE-TOKEN"] = token
+	}
+
+	var result []DiscoveredRepository
+	for page := 1; ; page++ {
+		var projects []gitlabProject
+		var requestUrl string = fmt.Sprintf("%s/api/v4/groups/%s/projects?include_subgroups=true&per_page=%d&page=%d",
+			baseUrl, url.PathEscape(source.Organization), gitlabPageSize, page)
+
+		if _, err := getJson(requestUrl, headers, &projects); err != nil {
+			return nil, err
+		}
+
+		for _, project := range projects {
+			// Older GitLab versions only know topics as tag_list
+			var topics []string = project.Topics
+			if len(topics) == 0 {
+				topics = project.TagList
+			}
+
+			result = append(result, DiscoveredRepository{
+				Name:     project.Path,
+				CloneUrl: project.HttpUrlToRepo,
+				Archived: project.Archived,
+				Fork:     project.ForkedFromProject != nil,
+				Topics:   topics,
+			})
+		}
+		if len(projects) < gitlabPageSize {
+			break
+		}
+	}
+
+	return result, nil
+}
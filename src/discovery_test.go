@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func githubStub(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/orgs/cloudfoundry/repos" && r.URL.Query().Get("page") == "1":
+			assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+			var repos []string
+			for i := 0; i < githubPageSize; i++ {
+				repos = append(repos, fmt.Sprintf(`{"name": "repo-%d", "clone_url": "https://github.com/cloudfoundry/repo-%d.git"}`, i, i))
+			}
+			fmt.Fprintf(w, "[%s]", strings.Join(repos, ","))
+		case r.URL.Path == "/orgs/cloudfoundry/repos":
+			fmt.Fprint(w, `[
+				{"name": "bosh", "clone_url": "https://github.com/cloudfoundry/bosh.git", "topics": ["bosh"]},
+				{"name": "bosh-old", "clone_url": "https://github.com/cloudfoundry/bosh-old.git", "archived": true},
+				{"name": "bosh-fork", "clone_url": "https://github.com/cloudfoundry/bosh-fork.git", "fork": true}
+			]`)
+		case r.URL.Path == "/users/victorfong/repos":
+			fmt.Fprint(w, `[{"name": "commit-count", "clone_url": "https://github.com/victorfong/commit-count.git"}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestGitHubProvider_Pages(t *testing.T) {
+	server := githubStub(t)
+	defer server.Close()
+
+	repos, err := GitHubProvider{}.ListRepositories(DiscoverySource{BaseUrl: server.URL, Organization: "cloudfoundry", Token: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, githubPageSize+3, len(repos))
+	assert.True(t, repos[githubPageSize+1].Archived)
+}
+
+func TestGitHubProvider_User(t *testing.T) {
+	server := githubStub(t)
+	defer server.Close()
+
+	repos, err := GitHubProvider{}.ListRepositories(DiscoverySource{BaseUrl: server.URL, Organization: "victorfong"})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(repos))
+
+	_, err = GitHubProvider{}.ListRepositories(DiscoverySource{BaseUrl: server.URL, Organization: "nobody"})
+	assert.NotNil(t, err)
+}
+
+func TestFilterDiscovered(t *testing.T) {
+	var repos []DiscoveredRepository = []DiscoveredRepository{
+		{Name: "bosh", Topics: []string{"bosh"}},
+		{Name: "bosh-old", Archived: true},
+		{Name: "bosh-fork", Fork: true},
+		{Name: "uaa"},
+	}
+
+	assert.Equal(t, 2, len(FilterDiscovered(repos, DiscoverySource{})))
+	assert.Equal(t, 4, len(FilterDiscovered(repos, DiscoverySource{IncludeArchived: true, IncludeForks: true})))
+	assert.Equal(t, 1, len(FilterDiscovered(repos, DiscoverySource{NamePattern: "bosh*"})))
+	assert.Equal(t, "bosh", FilterDiscovered(repos, DiscoverySource{Topics: []string{"bosh"}})[0].Name)
+}
+
+func TestRunDiscover(t *testing.T) {
+	server := githubStub(t)
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "commit-count-discover")
+	defer os.RemoveAll(dir)
+	var output string = filepath.Join(dir, "repos.txt")
+	ioutil.WriteFile(output, []byte("https://github.com/cloudfoundry/removed.git\n"), 0644)
+
+	var setting Setting = Setting{Discovery: []DiscoverySource{
+		{Provider: "github", BaseUrl: server.URL, Organization: "victorfong", Output: output},
+		{Provider: "github", BaseUrl: server.URL, Organization: "cloudfoundry", Token: "secret", NamePattern: "bosh*", Output: output},
+	}}
+	assert.Nil(t, RunDiscover(setting))
+
+	var repoMap map[string]string = getRepos(output)
+	assert.Equal(t, 2, len(repoMap))
+	assert.Equal(t, "https://github.com/cloudfoundry/bosh.git", repoMap["bosh"])
+	assert.Equal(t, "https://github.com/victorfong/commit-count.git", repoMap["commit-count"])
+
+	setting.Discovery[0].Provider = "bitbucket"
+	assert.NotNil(t, RunDiscover(setting))
+}
+
+func TestGitLabProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v4/groups/cloudfoundry/bosh/projects", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("include_subgroups"))
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		fmt.Fprint(w, `[
+			{"path": "bosh-director", "http_url_to_repo": "https://gitlab.example.com/cloudfoundry/bosh/bosh-director.git", "topics": ["bosh"]},
+			{"path": "bosh-agent", "http_url_to_repo": "https://gitlab.example.com/cloudfoundry/bosh/agents/bosh-agent.git", "tag_list": ["agent"], "archived": true},
+			{"path": "bosh-cli", "http_url_to_repo": "https://gitlab.example.com/cloudfoundry/bosh/bosh-cli.git", "forked_from_project": {"id": 7}}
+		]`)
+	}))
+	defer server.Close()
+
+	os.Setenv("GITLAB_TOKEN", "secret")
+	defer os.Unsetenv("GITLAB_TOKEN")
+
+	repos, err := DiscoverRepositories(DiscoverySource{Provider: "gitlab", BaseUrl: server.URL, Organization: "cloudfoundry/bosh", IncludeArchived: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []Repository{
+		{Name: "bosh-director", Url: "https://gitlab.example.com/cloudfoundry/bosh/bosh-director.git"},
+		{Name: "bosh-agent", Url: "https://gitlab.example.com/cloudfoundry/bosh/agents/bosh-agent.git"},
+	}, repos)
+
+	repos, _ = DiscoverRepositories(DiscoverySource{Provider: "gitlab", BaseUrl: server.URL, Organization: "cloudfoundry/bosh", Topics: []string{"agent"}, IncludeArchived: true})
+	assert.Equal(t, "bosh-agent", repos[0].Name)
+}
+
+func TestGiteaProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/orgs/platform/repos", r.URL.Path)
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		// The server caps pages at 2 repositories, below the requested limit
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `[
+				{"name": "router", "clone_url": "https://git.example.com/platform/router.git"},
+				{"name": "router-fork", "clone_url": "https://git.example.com/platform/router-fork.git", "fork": true}
+			]`)
+		case "2":
+			fmt.Fprint(w, `[{"name": "scheduler", "clone_url": "https://git.example.com/platform/scheduler.git"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	repos, err := DiscoverRepositories(DiscoverySource{Provider: "gitea", BaseUrl: server.URL, Organization: "platform", Token: "secret"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Repository{
+		{Name: "router", Url: "https://git.example.com/platform/router.git"},
+		{Name: "scheduler", Url: "https://git.example.com/platform/scheduler.git"},
+	}, repos)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Group is a portfolio of repositories: configured repositories listed by
+// name or tag, plus every url of a repos.txt style list given by import.
+type Group struct {
+	Name         string
+	Repositories []string
+	Tags         []string
+	Import       string
+}
+
+func normalizeUrl(url string) string {
+	url = strings.TrimSuffix(strings.TrimSpace(url), "/")
+	return strings.ToLower(strings.TrimSuffix(url, ".git"))
+}
+
+func hasAnyTag(repo Repository, tags []string) bool {
+	for _, tag := range tags {
+		for _, repoTag := range repo.Tags {
+			if tag == repoTag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ImportRepositories reads a repos.txt style list. The repositories use the
+// default ref selection of the setting.
+func ImportRepositories(setting Setting, filepath string) []Repository {
+	var repoMap map[string]string = getRepos(filepath)
+
+	var result []Repository
+	for name, url := range repoMap {
+		result = append(result, Repository{Name: name, Url: url, Refs: setting.Refs})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func GroupMembers(setting Setting, group Group) []Repository {
+	var result []Repository
+	for _, repo := range setting.Repositories {
+		if contains(group.Repositories, repo.Name) || hasAnyTag(repo, group.Tags) {
+			result = append(result, repo)
+		}
+	}
+	if group.Import != "" {
+		result = append(result, ImportRepositories(setting, group.Import)...)
+	}
+	return result
+}
+
+// GroupsByRepoName maps each member repository name to its group names.
+func GroupsByRepoName(setting Setting) map[string][]string {
+	var result map[string][]string = make(map[string][]string)
+	for _, group := range setting.Groups {
+		for _, repo := range GroupMembers(setting, group) {
+			if !contains(result[repo.Name], group.Name) {
+				result[repo.Name] = append(result[repo.Name], group.Name)
+			}
+		}
+	}
+	return result
+}
+
+// GroupsByUrl is GroupsByRepoName keyed by normalized url instead, so that
+// repos.txt entries can be matched against configured repositories.
+func GroupsByUrl(setting Setting) map[string][]string {
+	var result map[string][]string = make(map[string][]string)
+	for _, group := range setting.Groups {
+		for _, repo := range GroupMembers(setting, group) {
+			if repo.Url == "" {
+				continue
+			}
+			var url string = normalizeUrl(repo.Url)
+			if !contains(result[url], group.Name) {
+				result[url] = append(result[url], group.Name)
+			}
+		}
+	}
+	return result
+}
+
+// MatrixRepositories lists the configured repositories followed by group
+// imports that are not configured themselves. Only the former get columns
+// in result.csv; the latter only count towards their groups.
+func MatrixRepositories(setting Setting) []Repository {
+	var result []Repository = append([]Repository{}, setting.Repositories...)
+	var seen map[string]bool = make(map[string]bool)
+	for _, repo := range setting.Repositories {
+		seen[repo.Name] = true
+	}
+
+	for _, group := range setting.Groups {
+		if group.Import == "" {
+			continue
+		}
+		for _, repo := range ImportRepositories(setting, group.Import) {
+			if !seen[repo.Name] {
+				seen[repo.Name] = true
+				result = append(result, repo)
+			}
+		}
+	}
+	return result
+}
+
+// OverallRepositories is repos.txt together with every imported group list.
+func OverallRepositories(setting Setting, filepath string) map[string]string {
+	var result map[string]string = getRepos(filepath)
+	for _, group := range setting.Groups {
+		if group.Import == "" {
+			continue
+		}
+		for name, url := range getRepos(group.Import) {
+			result[name] = url
+		}
+	}
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, element := range list {
+		if element == value {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateGroupOutputFile writes the contributor x group roll-up of result.csv.
+func CreateGroupOutputFile(setting Setting, result map[string]map[string]int) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var header []string = []string{""}
+	for _, group := range setting.Groups {
+		header = append(header, group.Name)
+	}
+	writer.Write(header)
+
+	for _, contributor := range setting.Contributors {
+		var row []string = []string{contributor.Name}
+		for _, group := range setting.Groups {
+			row = append(row, strconv.Itoa(result[contributor.Name][group.Name]))
+		}
+		writer.Write(row)
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/result_groups.csv", buffer.Bytes(), 0644)
+}
+
+// CreateGroupTotalOutputFile writes the per group roll-up of total_count.csv
+// as group,domain,count rows.
+func CreateGroupTotalOutputFile(setting Setting, result map[string]map[string]int) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	for _, group := range setting.Groups {
+		var domains []string
+		for domain := range result[group.Name] {
+			domains = append(domains, domain)
+		}
+		sort.Strings(domains)
+
+		for _, domain := range domains {
+			writer.Write([]string{group.Name, domain, strconv.Itoa(result[group.Name][domain])})
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/total_count_groups.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_groups_data = `
+---
+groups:
+- name: BOSH
+  repositories:
+  - Bosh
+  tags:
+  - cpi
+- name: Buildpacks
+  import: test_repo.txt
+repositories:
+- name: Bosh
+  url: https://github.com/cloudfoundry/bosh.git
+- name: CF_BOSH_AWS_CPI
+  url: https://github.com/cloudfoundry-incubator/bosh-aws-cpi-release.git
+  tags:
+  - cpi
+- name: CF_UAA
+  url: https://github.com/cloudfoundry/uaa.git
+- name: API_Docs
+  url: https://github.com/cloudfoundry/api-docs
+contributors:
+- name: Victor Fong
+`
+
+func TestGroupMembers(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_groups_data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(setting.Groups))
+
+	var bosh []Repository = GroupMembers(setting, setting.Groups[0])
+	assert.Equal(t, 2, len(bosh))
+	assert.Equal(t, "Bosh", bosh[0].Name)
+	assert.Equal(t, "CF_BOSH_AWS_CPI", bosh[1].Name)
+
+	var buildpacks []Repository = GroupMembers(setting, setting.Groups[1])
+	assert.Equal(t, 2, len(buildpacks))
+	assert.Equal(t, "api-docs", buildpacks[0].Name)
+	assert.Equal(t, RefSelection{"all"}, buildpacks[0].Refs)
+}
+
+func TestGroupsByRepoNameAndUrl(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_groups_data))
+
+	var byName map[string][]string = GroupsByRepoName(setting)
+	assert.Equal(t, []string{"BOSH"}, byName["CF_BOSH_AWS_CPI"])
+	assert.Equal(t, []string{"Buildpacks"}, byName["binary-builder"])
+	assert.Equal(t, 0, len(byName["CF_UAA"]))
+
+	// API_Docs is configured without .git but matches the imported url
+	var byUrl map[string][]string = GroupsByUrl(setting)
+	assert.Equal(t, []string{"Buildpacks"}, byUrl[normalizeUrl("https://github.com/cloudfoundry/api-docs.git")])
+	assert.Equal(t, []string{"BOSH"}, byUrl[normalizeUrl("https://github.com/cloudfoundry/bosh.git")])
+}
+
+func TestMatrixAndOverallRepositories(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_groups_data))
+
+	var matrix []Repository = MatrixRepositories(setting)
+	assert.Equal(t, 6, len(matrix))
+	assert.Equal(t, "api-docs", matrix[4].Name)
+
+	var overall map[string]string = OverallRepositories(setting, "test_repo.txt")
+	assert.Equal(t, 2, len(overall))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Heatmap configures the working hours of the after-hours report: commits
+// on weekdays from WorkStart up to WorkEnd o'clock are within hours. Each
+// defaults separately, so 0 is a valid start.
+type Heatmap struct {
+	WorkStart *int `yaml:"work_start"`
+	WorkEnd   *int `yaml:"work_end"`
+}
+
+type WorkingHours struct {
+	Start int
+	End   int
+}
+
+var defaultWorkingHours WorkingHours = WorkingHours{Start: 9, End: 18}
+
+// Weekday x hour of day commit counts, indexed by time.Weekday.
+type ActivityHeatmap [7][24]int
+
+// The rows of heatmap.csv, starting the week on Monday.
+var heatmapWeekdays []time.Weekday = []time.Weekday{
+	time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday,
+}
+
+func (heatmap *ActivityHeatmap) add(date time.Time) {
+	heatmap[date.Weekday()][date.Hour()]++
+}
+
+func (heatmap ActivityHeatmap) Commits() int {
+	var result int = 0
+	for _, hours := range heatmap {
+		for _, count := range hours {
+			result += count
+		}
+	}
+	return result
+}
+
+// AfterHours counts the commits outside working hours on weekdays, and the
+// commits on weekends.
+func (heatmap ActivityHeatmap) AfterHours(hours WorkingHours) (int, int) {
+	var afterHours, weekend int = 0, 0
+	for weekday, counts := range heatmap {
+		for hour, count := range counts {
+			if time.Weekday(weekday) == time.Saturday || time.Weekday(weekday) == time.Sunday {
+				weekend += count
+			} else if hour < hours.Start || hour >= hours.End {
+				afterHours += count
+			}
+		}
+	}
+	return afterHours, weekend
+}
+
+// heatmapOwners credits a commit to its configured contributors and their
+// teams, and to the organizations of its author and co-author.
+func heatmapOwners(setting Setting, commit GitCommit) []SurvivalOwner {
+	var result []SurvivalOwner
+	for _, contributor := range setting.Contributors {
+		if contributor.Name != commit.Author && contributor.Name != commit.CoAuthor {
+			continue
+		}
+		result = append(result, SurvivalOwner{"contributor", contributor.Name})
+		if contributor.Team != "" {
+			result = appendOwner(result, SurvivalOwner{"team", contributor.Team})
+		}
+	}
+
+	result = appendOwner(result, SurvivalOwner{"organization", OrganizationOfDomain(setting, commit.AuthorDomain)})
+	if commit.CoAuthorDomain != "" {
+		result = appendOwner(result, SurvivalOwner{"organization", OrganizationOfDomain(setting, commit.CoAuthorDomain)})
+	}
+	return result
+}
+
+func appendOwner(owners []SurvivalOwner, owner SurvivalOwner) []SurvivalOwner {
+	for _, existing := range owners {
+		if existing == owner {
+			return owners
+		}
+	}
+	return append(owners, owner)
+}
+
+// BuildHeatmaps buckets the commits by weekday and hour in the author's own
+// local time, i.e. the offset the commit was recorded with, regardless of
+// the reporting timezone.
+func BuildHeatmaps(setting Setting, commits []GitCommit) map[SurvivalOwner]*ActivityHeatmap {
+	var result map[SurvivalOwner]*ActivityHeatmap = make(map[SurvivalOwner]*ActivityHeatmap)
+	for _, commit := range commits {
+		for _, owner := range heatmapOwners(setting, commit) {
+			if result[owner] == nil {
+				result[owner] = &ActivityHeatmap{}
+			}
+			result[owner].add(commit.Date)
+		}
+	}
+	return result
+}
+
+func (setting Setting) workingHours() WorkingHours {
+	var result WorkingHours = defaultWorkingHours
+	if setting.Heatmap.WorkStart != nil {
+		result.Start = *setting.Heatmap.WorkStart
+	}
+	if setting.Heatmap.WorkEnd != nil {
+		result.End = *setting.Heatmap.WorkEnd
+	}
+	return result
+}
+
+func validateWorkingHours(hours WorkingHours) error {
+	if hours.Start < 0 || hours.Start > 24 || hours.End < 0 || hours.End > 24 {
+		return fmt.Errorf("heatmap: work_start and work_end must be hours from 0 to 24")
+	}
+	if hours.Start >= hours.End {
+		return fmt.Errorf("heatmap: work_start %d is not before work_end %d", hours.Start, hours.End)
+	}
+	return nil
+}
+
+// CreateHeatmapOutputFiles writes work/heatmap.csv with a weekday x hour
+// table per window and owner, and work/after_hours.csv with the commits
+// outside working hours.
+func CreateHeatmapOutputFiles(setting Setting, history map[string][]GitCommit) {
+	var heatmapBuffer bytes.Buffer
+	var afterHoursBuffer bytes.Buffer
+	heatmapWriter := csv.NewWriter(&heatmapBuffer)
+	afterHoursWriter := csv.NewWriter(&afterHoursBuffer)
+
+	var repoNames []string
+	for repoName := range history {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	var header []string = []string{"Window", "Owner Type", "Owner", "Weekday"}
+	for hour := 0; hour < 24; hour++ {
+		header = append(header, fmt.Sprintf("%02d", hour))
+	}
+	heatmapWriter.Write(append(header, "Total"))
+	afterHoursWriter.Write([]string{"Window", "Owner Type", "Owner", "Commits", "After Hours", "Weekend", "Outside Hours Share"})
+
+	var hours WorkingHours = setting.workingHours()
+	for _, window := range setting.Windows {
+		var commits []GitCommit
+		for _, repoName := range repoNames {
+			commits = append(commits, CommitsInWindow(history[repoName], window)...)
+		}
+		var heatmaps map[SurvivalOwner]*ActivityHeatmap = BuildHeatmaps(setting, commits)
+
+		var owners []SurvivalOwner
+		for owner := range heatmaps {
+			owners = append(owners, owner)
+		}
+		sort.Slice(owners, func(i, j int) bool {
+			if owners[i].OwnerType != owners[j].OwnerType {
+				return owners[i].OwnerType < owners[j].OwnerType
+			}
+			return owners[i].Owner < owners[j].Owner
+		})
+		for _, owner := range owners {
+			var heatmap *ActivityHeatmap = heatmaps[owner]
+			for _, weekday := range heatmapWeekdays {
+				var row []string = []string{window.Name, owner.OwnerType, owner.Owner, weekday.String()}
+				var total int = 0
+				for _, count := range heatmap[weekday] {
+					row = append(row, strconv.Itoa(count))
+					total += count
+				}
+				heatmapWriter.Write(append(row, strconv.Itoa(total)))
+			}
+
+			afterHours, weekend := heatmap.AfterHours(hours)
+			afterHoursWriter.Write([]string{window.Name, owner.OwnerType, owner.Owner, strconv.Itoa(heatmap.Commits()),
+				strconv.Itoa(afterHours), strconv.Itoa(weekend), percentOf(afterHours+weekend, heatmap.Commits())})
+		}
+	}
+	heatmapWriter.Flush()
+	afterHoursWriter.Flush()
+
+	fmt.Print(afterHoursBuffer.String())
+	ioutil.WriteFile("work/heatmap.csv", heatmapBuffer.Bytes(), 0644)
+	ioutil.WriteFile("work/after_hours.csv", afterHoursBuffer.Bytes(), 0644)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildHeatmaps(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+	var setting Setting = Setting{
+		Contributors:  []Contributor{{Name: "Marco Voelz", Team: "BOSH"}, {Name: "Felix Riegger", Team: "BOSH"}},
+		Organizations: []Organization{{Name: "SAP", Domains: []string{"sap.com"}}},
+	}
+
+	// The reporting timezone does not move commits out of the author's hours
+	reportingLocation = time.FixedZone("JST", 9*60*60)
+	defer func() { reportingLocation = nil }()
+
+	var heatmaps map[SurvivalOwner]*ActivityHeatmap = BuildHeatmaps(setting, commits)
+	var marco *ActivityHeatmap = heatmaps[SurvivalOwner{"contributor", "Marco Voelz"}]
+	assert.Equal(t, 1, marco[time.Tuesday][17])
+	assert.Equal(t, 1, heatmaps[SurvivalOwner{"team", "BOSH"}][time.Tuesday][17])
+	assert.Equal(t, 5, heatmaps[SurvivalOwner{"organization", "SAP"}].Commits())
+}
+
+func TestActivityHeatmap_AfterHours(t *testing.T) {
+	var heatmap ActivityHeatmap
+	heatmap.add(time.Date(2015, 12, 29, 17, 56, 22, 0, time.FixedZone("", 60*60)))
+	heatmap.add(time.Date(2015, 12, 29, 18, 0, 0, 0, time.UTC))
+	heatmap.add(time.Date(2015, 12, 29, 8, 59, 0, 0, time.UTC))
+	heatmap.add(time.Date(2015, 12, 27, 12, 0, 0, 0, time.UTC))
+
+	afterHours, weekend := heatmap.AfterHours(defaultWorkingHours)
+	assert.Equal(t, 4, heatmap.Commits())
+	assert.Equal(t, 2, afterHours)
+	assert.Equal(t, 1, weekend)
+}
+
+func TestReadSetting_Heatmap(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte("contributors:\n- name: Victor Fong\n  team: BOSH\nheatmap:\n  work_start: 8\n  work_end: 17\n"))
+	assert.Equal(t, "BOSH", setting.Contributors[0].Team)
+	assert.Equal(t, WorkingHours{Start: 8, End: 17}, setting.workingHours())
+
+	setting, _ = UnmarshalYaml([]byte(test_data))
+	assert.Equal(t, defaultWorkingHours, setting.workingHours())
+
+	setting, err := UnmarshalYaml([]byte("heatmap:\n  work_start: 8\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, WorkingHours{Start: 8, End: 18}, setting.workingHours())
+
+	setting, _ = UnmarshalYaml([]byte("heatmap:\n  work_start: 0\n  work_end: 6\n"))
+	assert.Equal(t, WorkingHours{Start: 0, End: 6}, setting.workingHours())
+
+	_, err = UnmarshalYaml([]byte("heatmap:\n  work_start: 19\n"))
+	assert.NotNil(t, err)
+	_, err = UnmarshalYaml([]byte("heatmap:\n  work_end: 25\n"))
+	assert.NotNil(t, err)
+	_, err = UnmarshalYaml([]byte("heatmap:\n  work_start: -1\n"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The history store is an append-only file with one JSON run record per
+// line. It keeps the quarterly aggregates of every run, so trends can be
+// reported without parsing old history again.
+var storePath string = "work/store/runs.jsonl"
+
+type StoredCount struct {
+	Repo      string
+	Quarter   string
+	OwnerType string
+	Owner     string
+	Commits   int
+}
+
+type RunRecord struct {
+	Time       time.Time
+	ConfigHash string
+	// repo name -> HEAD commit
+	Heads  map[string]string
+	Counts []StoredCount
+}
+
+func ConfigHash(filepath string) string {
+	dat, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return ""
+	}
+	hash := sha1.Sum(dat)
+	return hex.EncodeToString(hash[:])
+}
+
+// RepositoryHeads records the HEAD of every repository that is on disk.
+func RepositoryHeads(repos []Repository) map[string]string {
+	var result map[string]string = make(map[string]string)
+	for _, repo := range repos {
+		output, err := gitOutput(repoDir(repo), "rev-parse", "HEAD")
+		if err != nil {
+			continue
+		}
+		result[repo.Name] = strings.TrimSpace(output)
+	}
+	return result
+}
+
+// CountQuarters aggregates the commits of each repository per quarter, for
+// every contributor and every organization. history is keyed by repo name
+// and already filtered by path.
+func CountQuarters(setting Setting, repos []Repository, history map[string][]GitCommit) []StoredCount {
+	var counts map[StoredCount]int = make(map[StoredCount]int)
+	for _, repo := range repos {
+		for _, commit := range history[repo.Name] {
+			var quarter string = QuarterOf(dayOf(commit.Date))
+			if isEmcCommit, contributorName := IsEmcCommit(commit, setting.Contributors); isEmcCommit {
+				counts[StoredCount{Repo: repo.Name, Quarter: quarter, OwnerType: "contributor", Owner: contributorName}]++
+			}
+			counts[StoredCount{Repo: repo.Name, Quarter: quarter, OwnerType: "organization",
+				Owner: OrganizationOfDomain(setting, commit.AuthorDomain)}]++
+		}
+	}
+
+	var result []StoredCount
+	for key, commits := range counts {
+		key.Commits = commits
+		result = append(result, key)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return storedCountKey(result[i]) < storedCountKey(result[j])
+	})
+	return result
+}
+
+func storedCountKey(count StoredCount) string {
+	return strings.Join([]string{count.Repo, count.Quarter, count.OwnerType, count.Owner}, "\x00")
+}
+
+func AppendRun(path string, record RunRecord) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	dat, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(dat, '\n'))
+	return err
+}
+
+// ReadRuns returns every run of the store, oldest first. Lines that do not
+// decode, e.g. from an interrupted write, are skipped.
+func ReadRuns(path string) []RunRecord {
+	var result []RunRecord
+
+	file, err := os.Open(path)
+	if err != nil {
+		return result
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var record RunRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+			result = append(result, record)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Time.Before(result[j].Time) })
+	return result
+}
+
+// LatestCounts merges the runs: the counts of a repository come from the
+// latest run that counted it, so repositories dropped from the setting keep
+// their history.
+func LatestCounts(runs []RunRecord) []StoredCount {
+	var latest map[string]int = make(map[string]int)
+	for i, run := range runs {
+		for _, count := range run.Counts {
+			latest[count.Repo] = i
+		}
+	}
+
+	var result []StoredCount
+	for i, run := range runs {
+		for _, count := range run.Counts {
+			if latest[count.Repo] == i {
+				result = append(result, count)
+			}
+		}
+	}
+	return result
+}
+
+type TrendRow struct {
+	OwnerType string
+	Owner     string
+	Quarter   string
+	Commits   int
+	// Commits of the quarter before and of the same quarter a year before
+	PreviousQuarter int
+	PreviousYear    int
+}
+
+func shiftQuarter(quarter string, quarters int) string {
+	year, _ := strconv.Atoi(quarter[:4])
+	number, _ := strconv.Atoi(quarter[5:])
+	var index int = year*4 + number - 1 + quarters
+	return fmt.Sprintf("%dQ%d", index/4, index%4+1)
+}
+
+// BuildTrend sums the counts over repositories per organization and
+// contributor, plus the repositories themselves, and pairs each quarter
+// with the quarter before and the same quarter of the year before.
+func BuildTrend(counts []StoredCount) []TrendRow {
+	var totals map[TrendRow]int = make(map[TrendRow]int)
+	var quarters map[string]bool = make(map[string]bool)
+	for _, count := range counts {
+		quarters[count.Quarter] = true
+		totals[TrendRow{OwnerType: count.OwnerType, Owner: count.Owner, Quarter: count.Quarter}] += count.Commits
+		if count.OwnerType == "organization" {
+			totals[TrendRow{OwnerType: "repo", Owner: count.Repo, Quarter: count.Quarter}] += count.Commits
+		}
+	}
+
+	var owners map[TrendRow]bool = make(map[TrendRow]bool)
+	for key := range totals {
+		owners[TrendRow{OwnerType: key.OwnerType, Owner: key.Owner}] = true
+	}
+
+	var sortedQuarters []string
+	for quarter := range quarters {
+		sortedQuarters = append(sortedQuarters, quarter)
+	}
+	sort.Strings(sortedQuarters)
+
+	var result []TrendRow
+	for owner := range owners {
+		for _, quarter := range sortedQuarters {
+			var key TrendRow = TrendRow{OwnerType: owner.OwnerType, Owner: owner.Owner}
+			var row TrendRow = key
+			row.Quarter = quarter
+			row.Commits = totals[TrendRow{OwnerType: key.OwnerType, Owner: key.Owner, Quarter: quarter}]
+			row.PreviousQuarter = totals[TrendRow{OwnerType: key.OwnerType, Owner: key.Owner, Quarter: shiftQuarter(quarter, -1)}]
+			row.PreviousYear = totals[TrendRow{OwnerType: key.OwnerType, Owner: key.Owner, Quarter: shiftQuarter(quarter, -4)}]
+			result = append(result, row)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].OwnerType != result[j].OwnerType {
+			return result[i].OwnerType < result[j].OwnerType
+		}
+		if result[i].Owner != result[j].Owner {
+			return result[i].Owner < result[j].Owner
+		}
+		return result[i].Quarter < result[j].Quarter
+	})
+	return result
+}
+
+// formatChange is the change from previous to current in percent, empty
+// when there is nothing to compare with.
+func formatChange(current int, previous int) string {
+	if previous == 0 {
+		return ""
+	}
+	var change string = formatShare(100 * float64(current-previous) / float64(previous))
+	if current > previous {
+		return "+" + change
+	}
+	return change
+}
+
+// RecordRun appends the aggregates of this run to the history store.
+func RecordRun(setting Setting, history map[string][]GitCommit, now time.Time) error {
+	var repos []Repository = MatrixRepositories(setting)
+	return AppendRun(storePath, RunRecord{
+		Time:       now,
+		ConfigHash: ConfigHash("setting.yml"),
+		Heads:      RepositoryHeads(repos),
+		Counts:     CountQuarters(setting, repos, history),
+	})
+}
+
+// CreateTrendOutputFile writes the quarter-over-quarter and year-over-year
+// table of the history store to work/trend.csv.
+func CreateTrendOutputFile(runs []RunRecord) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Owner Type", "Owner", "Quarter", "Commits", "Previous Quarter", "QoQ", "Previous Year", "YoY"})
+	for _, row := range BuildTrend(LatestCounts(runs)) {
+		writer.Write([]string{row.OwnerType, row.Owner, row.Quarter, strconv.Itoa(row.Commits),
+			strconv.Itoa(row.PreviousQuarter), formatChange(row.Commits, row.PreviousQuarter),
+			strconv.Itoa(row.PreviousYear), formatChange(row.Commits, row.PreviousYear)})
+	}
+	writer.Flush()
+
+	fmt.Printf("Trend of %d runs\n", len(runs))
+	ioutil.WriteFile("work/trend.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountQuarters(t *testing.T) {
+	var setting Setting = Setting{
+		Contributors:  []Contributor{{Name: "Victor Fong"}},
+		Organizations: []Organization{{Name: "EMC", Domains: []string{"emc.com"}}},
+	}
+	var repos []Repository = []Repository{{Name: "Bosh"}}
+	var history map[string][]GitCommit = map[string][]GitCommit{"Bosh": {
+		{Author: "Victor Fong", AuthorDomain: "emc.com", Date: time.Date(2015, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{Author: "Victor Fong", AuthorDomain: "emc.com", Date: time.Date(2015, 11, 2, 0, 0, 0, 0, time.UTC)},
+		{Author: "Chris Piraino", AuthorDomain: "pivotal.io", Date: time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}}
+
+	assert.Equal(t, []StoredCount{
+		{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "contributor", Owner: "Victor Fong", Commits: 2},
+		{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 2},
+		{Repo: "Bosh", Quarter: "2016Q1", OwnerType: "organization", Owner: "pivotal.io", Commits: 1},
+	}, CountQuarters(setting, repos, history))
+}
+
+func TestAppendRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commit-count-store")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var path string = filepath.Join(dir, "store", "runs.jsonl")
+	var first RunRecord = RunRecord{Time: time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC), ConfigHash: "abc",
+		Heads: map[string]string{"Bosh": "a39b69d"}}
+	var second RunRecord = RunRecord{Time: time.Date(2016, 1, 11, 0, 0, 0, 0, time.UTC), ConfigHash: "abc",
+		Counts: []StoredCount{{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 2}}}
+	assert.Nil(t, AppendRun(path, first))
+	assert.Nil(t, AppendRun(path, second))
+
+	var runs []RunRecord = ReadRuns(path)
+	assert.Equal(t, 2, len(runs))
+	assert.Equal(t, "a39b69d", runs[0].Heads["Bosh"])
+	assert.Equal(t, second.Counts, runs[1].Counts)
+}
+
+func TestLatestCounts(t *testing.T) {
+	var runs []RunRecord = []RunRecord{
+		{Counts: []StoredCount{
+			{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 2},
+			{Repo: "Diego", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 1},
+		}},
+		{Counts: []StoredCount{
+			{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 3},
+		}},
+	}
+
+	assert.Equal(t, []StoredCount{
+		{Repo: "Diego", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 1},
+		{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 3},
+	}, LatestCounts(runs))
+}
+
+func TestShiftQuarter(t *testing.T) {
+	assert.Equal(t, "2015Q4", shiftQuarter("2016Q1", -1))
+	assert.Equal(t, "2015Q1", shiftQuarter("2016Q1", -4))
+	assert.Equal(t, "2016Q1", shiftQuarter("2015Q4", 1))
+}
+
+func TestBuildTrend(t *testing.T) {
+	var counts []StoredCount = []StoredCount{
+		{Repo: "Bosh", Quarter: "2015Q1", OwnerType: "organization", Owner: "EMC", Commits: 2},
+		{Repo: "Bosh", Quarter: "2015Q4", OwnerType: "organization", Owner: "EMC", Commits: 4},
+		{Repo: "Bosh", Quarter: "2016Q1", OwnerType: "organization", Owner: "EMC", Commits: 5},
+	}
+	var rows []TrendRow = BuildTrend(counts)
+
+	assert.Equal(t, 6, len(rows))
+	assert.Equal(t, TrendRow{OwnerType: "organization", Owner: "EMC", Quarter: "2016Q1",
+		Commits: 5, PreviousQuarter: 4, PreviousYear: 2}, rows[2])
+	assert.Equal(t, TrendRow{OwnerType: "repo", Owner: "Bosh", Quarter: "2016Q1",
+		Commits: 5, PreviousQuarter: 4, PreviousYear: 2}, rows[5])
+}
+
+func TestFormatChange(t *testing.T) {
+	assert.Equal(t, "+25.0", formatChange(5, 4))
+	assert.Equal(t, "-50.0", formatChange(1, 2))
+	assert.Equal(t, "", formatChange(3, 0))
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// LeaderboardEntry is one author identity of a repository. Authors and
+// co-authors both get credit for a commit, as in the overall count.
+type LeaderboardEntry struct {
+	Author       string
+	Domain       string
+	Organization string
+	Commits      int
+	Rank         int
+	Percentile   float64
+	Ours         bool
+}
+
+func isContributor(name string, contributors []Contributor) bool {
+	for _, contributor := range contributors {
+		if contributor.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildLeaderboard ranks every author of the commits, most commits first.
+// Ties share a rank. Percentile is the share of authors with at most as
+// many commits, so the top author is at 100.
+func BuildLeaderboard(setting Setting, commits []GitCommit) []LeaderboardEntry {
+	var counts map[string]int = make(map[string]int)
+	var domains map[string]string = make(map[string]string)
+
+	credit := func(author string, domain string) {
+		if author == "" {
+			return
+		}
+		counts[author]++
+		if domains[author] == "" {
+			domains[author] = domain
+		}
+	}
+	for _, commit := range commits {
+		credit(commit.Author, commit.AuthorDomain)
+		credit(commit.CoAuthor, commit.CoAuthorDomain)
+	}
+
+	var result []LeaderboardEntry
+	for author, count := range counts {
+		result = append(result, LeaderboardEntry{
+			Author:       author,
+			Domain:       domains[author],
+			Organization: OrganizationOfDomain(setting, domains[author]),
+			Commits:      count,
+			Ours:         isContributor(author, setting.Contributors),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Commits != result[j].Commits {
+			return result[i].Commits > result[j].Commits
+		}
+		return result[i].Author < result[j].Author
+	})
+
+	for i := range result {
+		if i > 0 && result[i].Commits == result[i-1].Commits {
+			result[i].Rank = result[i-1].Rank
+		} else {
+			result[i].Rank = i + 1
+		}
+	}
+	for i := range result {
+		var atMost int = 0
+		for _, other := range result {
+			if other.Commits <= result[i].Commits {
+				atMost++
+			}
+		}
+		result[i].Percentile = 100 * float64(atMost) / float64(len(result))
+	}
+
+	return result
+}
+
+func CreateLeaderboardOutputFile(setting Setting, history map[string][]GitCommit) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var repoNames []string
+	for repoName := range history {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	writer.Write([]string{"Code Repo", "Rank", "Author", "Domain", "Organization", "Commits", "Percentile", "Ours"})
+	for _, repoName := range repoNames {
+		for _, entry := range BuildLeaderboard(setting, history[repoName]) {
+			var ours string = ""
+			if entry.Ours {
+				ours = "yes"
+				fmt.Printf("%s ranks #%d of %s with %d commits (%.1f percentile)\n",
+					entry.Author, entry.Rank, repoName, entry.Commits, entry.Percentile)
+			}
+			writer.Write([]string{repoName, strconv.Itoa(entry.Rank), entry.Author, entry.Domain,
+				entry.Organization, strconv.Itoa(entry.Commits), strconv.FormatFloat(entry.Percentile, 'f', 1, 64), ours})
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/leaderboard.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_leaderboard_data = `
+---
+organizations:
+- name: SAP
+  domains:
+  - sap.com
+contributors:
+- name: Beyhan Veli
+`
+
+func TestBuildLeaderboard(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_leaderboard_data))
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+	commits = append(commits, GitCommit{Author: "Devin Fallak", AuthorDomain: "pivotal.io"})
+
+	var leaderboard []LeaderboardEntry = BuildLeaderboard(setting, commits)
+	assert.Equal(t, 4, len(leaderboard))
+
+	assert.Equal(t, LeaderboardEntry{Author: "Marco Voelz", Domain: "sap.com", Organization: "SAP",
+		Commits: 3, Rank: 1, Percentile: 100}, leaderboard[0])
+	assert.Equal(t, LeaderboardEntry{Author: "Beyhan Veli", Domain: "sap.com", Organization: "SAP",
+		Commits: 2, Rank: 2, Percentile: 75, Ours: true}, leaderboard[1])
+
+	// Ties share the rank
+	assert.Equal(t, "Devin Fallak", leaderboard[2].Author)
+	assert.Equal(t, "pivotal.io", leaderboard[2].Organization)
+	assert.Equal(t, 3, leaderboard[2].Rank)
+	assert.Equal(t, 3, leaderboard[3].Rank)
+	assert.Equal(t, 50.0, leaderboard[3].Percentile)
+}
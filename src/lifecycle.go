@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Lifecycle configures when a contributor counts as gone quiet on a
+// repository: no commit for more than IdleDays days.
+type Lifecycle struct {
+	IdleDays int `yaml:"idle_days"`
+}
+
+const defaultIdleDays = 90
+
+type LifecycleReport struct {
+	FirstCommit   time.Time
+	LastCommit    time.Time
+	ActiveDays    int
+	ActiveWeeks   int
+	LongestStreak int
+	GoneQuiet     bool
+}
+
+// CommitContributors lists every configured contributor on the commit, so
+// both people of a pair are credited.
+func CommitContributors(commit GitCommit, contributors []Contributor) []string {
+	var result []string
+	for _, contributor := range contributors {
+		if contributor.Name == commit.Author || contributor.Name == commit.CoAuthor {
+			result = append(result, contributor.Name)
+		}
+	}
+	return result
+}
+
+// dayOf is the calendar day of date in the reporting timezone, as midnight
+// UTC so that it compares with window dates.
+func dayOf(date time.Time) time.Time {
+	date = reportingTime(date)
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// BuildLifecycle summarizes the dates of one contributor's commits. now is
+// the reference for the idle period.
+func BuildLifecycle(dates []time.Time, idleDays int, now time.Time) LifecycleReport {
+	var result LifecycleReport
+	if len(dates) == 0 {
+		return result
+	}
+
+	var days map[time.Time]bool = make(map[time.Time]bool)
+	var weeks map[string]bool = make(map[string]bool)
+	for _, date := range dates {
+		var day time.Time = dayOf(date)
+		days[day] = true
+		year, week := day.ISOWeek()
+		weeks[strconv.Itoa(year)+"-"+strconv.Itoa(week)] = true
+
+		if result.FirstCommit.IsZero() || day.Before(result.FirstCommit) {
+			result.FirstCommit = day
+		}
+		if day.After(result.LastCommit) {
+			result.LastCommit = day
+		}
+	}
+
+	var sortedDays []time.Time
+	for day := range days {
+		sortedDays = append(sortedDays, day)
+	}
+	sort.Slice(sortedDays, func(i, j int) bool { return sortedDays[i].Before(sortedDays[j]) })
+
+	var streak int = 0
+	for i, day := range sortedDays {
+		if i > 0 && day.Sub(sortedDays[i-1]) == 24*time.Hour {
+			streak++
+		} else {
+			streak = 1
+		}
+		if streak > result.LongestStreak {
+			result.LongestStreak = streak
+		}
+	}
+
+	result.ActiveDays = len(days)
+	result.ActiveWeeks = len(weeks)
+	result.GoneQuiet = now.Sub(result.LastCommit) > time.Duration(idleDays)*24*time.Hour
+	return result
+}
+
+// CommitDates collects the commit dates of each configured contributor per
+// repository: contributor -> repo -> dates.
+func CommitDates(setting Setting, history map[string][]GitCommit) map[string]map[string][]time.Time {
+	var result map[string]map[string][]time.Time = make(map[string]map[string][]time.Time)
+	for _, contributor := range setting.Contributors {
+		result[contributor.Name] = make(map[string][]time.Time)
+	}
+
+	for repoName, commits := range history {
+		for _, commit := range commits {
+			for _, contributorName := range CommitContributors(commit, setting.Contributors) {
+				result[contributorName][repoName] = append(result[contributorName][repoName], commit.Date)
+			}
+		}
+	}
+	return result
+}
+
+func CreateLifecycleOutputFile(setting Setting, history map[string][]GitCommit, now time.Time) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	var idleDays int = setting.Lifecycle.IdleDays
+	if idleDays == 0 {
+		idleDays = defaultIdleDays
+	}
+
+	var dates map[string]map[string][]time.Time = CommitDates(setting, history)
+	writer.Write([]string{"Contributor", "Code Repo", "First Commit", "Last Commit",
+		"Active Days", "Active Weeks", "Longest Streak", "Gone Quiet"})
+	for _, contributor := range setting.Contributors {
+		var repoNames []string
+		for repoName := range dates[contributor.Name] {
+			repoNames = append(repoNames, repoName)
+		}
+		sort.Strings(repoNames)
+
+		for _, repoName := range repoNames {
+			var report LifecycleReport = BuildLifecycle(dates[contributor.Name][repoName], idleDays, now)
+			var goneQuiet string = ""
+			if report.GoneQuiet {
+				goneQuiet = "yes"
+			}
+
+			writer.Write([]string{contributor.Name, repoName,
+				report.FirstCommit.Format("2006-01-02"), report.LastCommit.Format("2006-01-02"),
+				strconv.Itoa(report.ActiveDays), strconv.Itoa(report.ActiveWeeks),
+				strconv.Itoa(report.LongestStreak), goneQuiet})
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/lifecycle.csv", buffer.Bytes(), 0644)
+}
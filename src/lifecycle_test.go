@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildLifecycle(t *testing.T) {
+	var dates []time.Time = []time.Time{
+		getDate("2015-12-29"), getDate("2015-12-28"), getDate("2015-12-28"),
+		getDate("2015-12-22"), getDate("2015-12-30"), getDate("2015-10-15"),
+	}
+
+	var report LifecycleReport = BuildLifecycle(dates, 90, getDate("2016-01-15"))
+	assert.True(t, getDate("2015-10-15").Equal(report.FirstCommit))
+	assert.True(t, getDate("2015-12-30").Equal(report.LastCommit))
+	assert.Equal(t, 5, report.ActiveDays)
+	assert.Equal(t, 3, report.ActiveWeeks)
+	assert.Equal(t, 3, report.LongestStreak)
+	assert.False(t, report.GoneQuiet)
+
+	assert.True(t, BuildLifecycle(dates, 10, getDate("2016-01-15")).GoneQuiet)
+	assert.Equal(t, LifecycleReport{}, BuildLifecycle(nil, 90, getDate("2016-01-15")))
+}
+
+func TestCommitDates(t *testing.T) {
+	var setting Setting = Setting{Contributors: []Contributor{{Name: "Beyhan Veli"}, {Name: "Felix Riegger"}}}
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var history map[string][]GitCommit = map[string][]GitCommit{"repo1": ReadCommit(scanner, "repo1")}
+
+	var result map[string]map[string][]time.Time = CommitDates(setting, history)
+	assert.Equal(t, 2, len(result["Beyhan Veli"]["repo1"]))
+	assert.Equal(t, 1, len(result["Felix Riegger"]["repo1"]))
+}
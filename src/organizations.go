@@ -0,0 +1,47 @@
+package main
+
+import "strings"
+
+// Organization names a company by the email domains of its people and by
+// the code hosting accounts (users or orgs) its pull requests come from.
+type Organization struct {
+	Name    string
+	Domains []string
+	Owners  []string
+}
+
+const otherOrganization = "Other"
+
+func containsFold(list []string, value string) bool {
+	for _, element := range list {
+		if strings.EqualFold(element, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// OrganizationOfOwner returns the organization a fork owner belongs to, or
+// Other when no organization lists it.
+func OrganizationOfOwner(setting Setting, owner string) string {
+	for _, organization := range setting.Organizations {
+		if containsFold(organization.Owners, owner) {
+			return organization.Name
+		}
+	}
+	return otherOrganization
+}
+
+// OrganizationOfDomain returns the organization an email domain belongs
+// to. Domains no organization lists stand for themselves.
+func OrganizationOfDomain(setting Setting, domain string) string {
+	for _, organization := range setting.Organizations {
+		if containsFold(organization.Domains, domain) {
+			return organization.Name
+		}
+	}
+	if domain == "" {
+		return otherOrganization
+	}
+	return domain
+}
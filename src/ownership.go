@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ownership configures the directory ownership report. Depth is how many
+// directory levels are reported. With Codeowners set, a CODEOWNERS file is
+// suggested per repository from the last RecentDays of history, listing the
+// contributors with at least MinShare percent of a directory's churn.
+type Ownership struct {
+	Depth      int
+	Codeowners bool
+	RecentDays int     `yaml:"recent_days"`
+	MinShare   float64 `yaml:"min_share"`
+}
+
+var defaultOwnership Ownership = Ownership{Depth: 2, RecentDays: 180, MinShare: 25}
+
+const rootDirectory = "."
+
+type DirectoryStats struct {
+	Commits             int
+	Churn               int
+	ContributorCommits  map[string]int
+	ContributorChurn    map[string]int
+	OrganizationCommits map[string]int
+	OrganizationChurn   map[string]int
+}
+
+func newDirectoryStats() *DirectoryStats {
+	return &DirectoryStats{
+		ContributorCommits:  make(map[string]int),
+		ContributorChurn:    make(map[string]int),
+		OrganizationCommits: make(map[string]int),
+		OrganizationChurn:   make(map[string]int),
+	}
+}
+
+func (setting Setting) ownership() Ownership {
+	var result Ownership = setting.Ownership
+	if result.Depth == 0 {
+		result.Depth = defaultOwnership.Depth
+	}
+	if result.RecentDays == 0 {
+		result.RecentDays = defaultOwnership.RecentDays
+	}
+	if result.MinShare == 0 {
+		result.MinShare = defaultOwnership.MinShare
+	}
+	return result
+}
+
+// DirectoryPrefixes lists the root and the parent directories of a file,
+// up to depth levels: "src/a/b/c.go" at depth 2 is ".", "src" and "src/a".
+func DirectoryPrefixes(filePath string, depth int) []string {
+	var result []string = []string{rootDirectory}
+	var elements []string = strings.Split(filePath, "/")
+	for i := 1; i < len(elements) && i <= depth; i++ {
+		result = append(result, strings.Join(elements[:i], "/"))
+	}
+	return result
+}
+
+// BuildOwnership credits the commits and churn touching each directory to
+// the configured contributors and to the organizations of both authors.
+func BuildOwnership(setting Setting, commits []GitCommit, depth int) map[string]*DirectoryStats {
+	var result map[string]*DirectoryStats = make(map[string]*DirectoryStats)
+
+	for _, commit := range commits {
+		var churn map[string]int = make(map[string]int)
+		for _, file := range commit.Files {
+			for _, directory := range DirectoryPrefixes(file.Path, depth) {
+				churn[directory] += file.Added + file.Deleted
+			}
+		}
+
+		var contributors []string = CommitContributors(commit, setting.Contributors)
+		var organizations []string
+		for _, author := range []struct{ name, domain string }{
+			{commit.Author, commit.AuthorDomain},
+			{commit.CoAuthor, commit.CoAuthorDomain},
+		} {
+			if author.name != "" {
+				var organization string = OrganizationOfDomain(setting, author.domain)
+				if !contains(organizations, organization) {
+					organizations = append(organizations, organization)
+				}
+			}
+		}
+
+		for directory, directoryChurn := range churn {
+			if result[directory] == nil {
+				result[directory] = newDirectoryStats()
+			}
+			var stats *DirectoryStats = result[directory]
+			stats.Commits++
+			stats.Churn += directoryChurn
+			for _, contributor := range contributors {
+				stats.ContributorCommits[contributor]++
+				stats.ContributorChurn[contributor] += directoryChurn
+			}
+			for _, organization := range organizations {
+				stats.OrganizationCommits[organization]++
+				stats.OrganizationChurn[organization] += directoryChurn
+			}
+		}
+	}
+	return result
+}
+
+func sortedDirectories(stats map[string]*DirectoryStats) []string {
+	var result []string
+	for directory := range stats {
+		result = append(result, directory)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func percentOf(part int, total int) string {
+	if total == 0 {
+		return formatShare(0)
+	}
+	return formatShare(100 * float64(part) / float64(total))
+}
+
+func CreateOwnershipOutputFile(setting Setting, history map[string][]GitCommit, now time.Time) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	var ownership Ownership = setting.ownership()
+
+	var repoNames []string
+	for repoName := range history {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	writer.Write([]string{"Code Repo", "Directory", "Owner Type", "Owner", "Commits", "Commit Share", "Churn", "Churn Share"})
+	for _, repoName := range repoNames {
+		var stats map[string]*DirectoryStats = BuildOwnership(setting, history[repoName], ownership.Depth)
+		for _, directory := range sortedDirectories(stats) {
+			var directoryStats *DirectoryStats = stats[directory]
+			for _, owners := range []struct {
+				ownerType string
+				commits   map[string]int
+				churn     map[string]int
+			}{
+				{"contributor", directoryStats.ContributorCommits, directoryStats.ContributorChurn},
+				{"organization", directoryStats.OrganizationCommits, directoryStats.OrganizationChurn},
+			} {
+				for _, owner := range sortedByCount(owners.churn) {
+					writer.Write([]string{repoName, directory, owners.ownerType, owner,
+						strconv.Itoa(owners.commits[owner]), percentOf(owners.commits[owner], directoryStats.Commits),
+						strconv.Itoa(owners.churn[owner]), percentOf(owners.churn[owner], directoryStats.Churn)})
+				}
+			}
+		}
+
+		if ownership.Codeowners {
+			var recent Window = Window{
+				Name:  "recent",
+				Begin: now.AddDate(0, 0, -ownership.RecentDays).Format("2006-01-02"),
+				End:   now.AddDate(0, 0, 1).Format("2006-01-02"),
+			}
+			var recentStats map[string]*DirectoryStats = BuildOwnership(setting, CommitsInWindow(history[repoName], recent), ownership.Depth)
+			ioutil.WriteFile("work/CODEOWNERS_"+safeFileName(repoName),
+				[]byte(SuggestCodeowners(setting, recentStats, ownership.MinShare)), 0644)
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/ownership.csv", buffer.Bytes(), 0644)
+}
+
+func contributorHandle(setting Setting, name string) string {
+	for _, contributor := range setting.Contributors {
+		if contributor.Name == name {
+			return contributor.Handle
+		}
+	}
+	return ""
+}
+
+// SuggestCodeowners lists, per directory, the configured contributors with
+// at least minShare percent of its churn. Later lines win in CODEOWNERS, so
+// directories are written from the root down.
+func SuggestCodeowners(setting Setting, stats map[string]*DirectoryStats, minShare float64) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("# Suggested by commit-count from recent activity\n")
+
+	for _, directory := range sortedDirectories(stats) {
+		var directoryStats *DirectoryStats = stats[directory]
+		var owners []string
+		var missing []string
+		for _, contributor := range sortedByCount(directoryStats.ContributorChurn) {
+			if directoryStats.Churn == 0 ||
+				100*float64(directoryStats.ContributorChurn[contributor])/float64(directoryStats.Churn) < minShare {
+				continue
+			}
+			if handle := contributorHandle(setting, contributor); handle != "" {
+				owners = append(owners, handle)
+			} else {
+				missing = append(missing, contributor)
+			}
+		}
+
+		var pattern string = "/" + directory + "/"
+		if directory == rootDirectory {
+			pattern = "*"
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(&buffer, "# %s: no handle for %s\n", pattern, strings.Join(missing, ", "))
+		}
+		if len(owners) > 0 {
+			fmt.Fprintf(&buffer, "%s %s\n", pattern, strings.Join(owners, " "))
+		}
+	}
+	return buffer.String()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectoryPrefixes(t *testing.T) {
+	assert.Equal(t, []string{".", "src", "src/bosh-director"}, DirectoryPrefixes("src/bosh-director/lib/director.rb", 2))
+	assert.Equal(t, []string{"."}, DirectoryPrefixes("README.md", 2))
+	assert.Equal(t, []string{".", "docs"}, DirectoryPrefixes("docs/a/b.md", 1))
+}
+
+var test_ownership_data = `
+---
+organizations:
+- name: SAP
+  domains:
+  - sap.com
+contributors:
+- name: Marco Voelz
+  handle: "@voelzmo"
+- name: Beyhan Veli
+`
+
+func TestBuildOwnership(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_ownership_data))
+	var stats map[string]*DirectoryStats = BuildOwnership(setting, readNumstatCommits(), 2)
+
+	assert.Equal(t, []string{".", "docs", "src", "src/bosh-director"}, sortedDirectories(stats))
+	assert.Equal(t, 3, stats["."].Commits)
+	assert.Equal(t, 28, stats["."].Churn)
+	assert.Equal(t, 2, stats["src/bosh-director"].ContributorCommits["Marco Voelz"])
+	assert.Equal(t, 26, stats["src/bosh-director"].ContributorChurn["Marco Voelz"])
+	assert.Equal(t, 2, stats["docs"].ContributorChurn["Beyhan Veli"])
+	assert.Equal(t, 3, stats["."].OrganizationCommits["SAP"])
+	assert.Equal(t, 28, stats["."].OrganizationChurn["SAP"])
+}
+
+func TestSuggestCodeowners(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_ownership_data))
+	var stats map[string]*DirectoryStats = BuildOwnership(setting, readNumstatCommits(), 1)
+
+	assert.Equal(t, `# Suggested by commit-count from recent activity
+* @voelzmo
+# /docs/: no handle for Beyhan Veli
+/src/ @voelzmo
+`, SuggestCodeowners(setting, stats, 25))
+}
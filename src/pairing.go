@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// Pairing counts, for each configured contributor, the commits made
+// together with each partner (author and co-author of the same commit).
+// Partners do not need to be configured contributors.
+type Pairing struct {
+	// contributor -> partner -> commits
+	Pairs map[string]map[string]int
+	// contributor -> commits, paired or not
+	Commits map[string]int
+	// contributor -> commits with a partner
+	Paired map[string]int
+}
+
+func NewPairing() Pairing {
+	return Pairing{
+		Pairs:   make(map[string]map[string]int),
+		Commits: make(map[string]int),
+		Paired:  make(map[string]int),
+	}
+}
+
+func (pairing Pairing) Add(setting Setting, commits []GitCommit) {
+	for _, commit := range commits {
+		for _, contributorName := range CommitContributors(commit, setting.Contributors) {
+			pairing.Commits[contributorName]++
+
+			var partner string = commit.CoAuthor
+			if contributorName == commit.CoAuthor {
+				partner = commit.Author
+			}
+			if partner == "" || partner == contributorName {
+				continue
+			}
+
+			pairing.Paired[contributorName]++
+			if pairing.Pairs[contributorName] == nil {
+				pairing.Pairs[contributorName] = make(map[string]int)
+			}
+			pairing.Pairs[contributorName][partner]++
+		}
+	}
+}
+
+func (pairing Pairing) Rate(contributorName string) float64 {
+	if pairing.Commits[contributorName] == 0 {
+		return 0
+	}
+	return 100 * float64(pairing.Paired[contributorName]) / float64(pairing.Commits[contributorName])
+}
+
+// PairingByRepo builds the pairing of every repository within the window,
+// plus the pairing across all repositories under the key "".
+func PairingByRepo(setting Setting, history map[string][]GitCommit, window Window) map[string]Pairing {
+	var result map[string]Pairing = map[string]Pairing{"": NewPairing()}
+	for repoName, commits := range history {
+		var inWindow []GitCommit = CommitsInWindow(commits, window)
+		result[repoName] = NewPairing()
+		result[repoName].Add(setting, inWindow)
+		result[""].Add(setting, inWindow)
+	}
+	return result
+}
+
+// CreatePairingOutputFiles writes work/pairing.csv with the pairs per
+// window and repo, work/pairing_rate.csv with each contributor's share of
+// paired commits, and a Graphviz graph per window, work/pairing_<window>.dot.
+func CreatePairingOutputFiles(setting Setting, history map[string][]GitCommit) {
+	var pairsBuffer bytes.Buffer
+	var ratesBuffer bytes.Buffer
+	pairsWriter := csv.NewWriter(&pairsBuffer)
+	ratesWriter := csv.NewWriter(&ratesBuffer)
+
+	var repoNames []string
+	for repoName := range history {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	pairsWriter.Write([]string{"Window", "Code Repo", "Contributor", "Partner", "Commits"})
+	ratesWriter.Write([]string{"Window", "Contributor", "Commits", "Paired Commits", "Pairing Rate"})
+	for _, window := range setting.Windows {
+		var pairings map[string]Pairing = PairingByRepo(setting, history, window)
+
+		for _, repoName := range repoNames {
+			for _, contributor := range setting.Contributors {
+				var partners map[string]int = pairings[repoName].Pairs[contributor.Name]
+				for _, partner := range sortedByCount(partners) {
+					pairsWriter.Write([]string{window.Name, repoName, contributor.Name, partner, strconv.Itoa(partners[partner])})
+				}
+			}
+		}
+
+		var all Pairing = pairings[""]
+		for _, contributor := range setting.Contributors {
+			ratesWriter.Write([]string{window.Name, contributor.Name, strconv.Itoa(all.Commits[contributor.Name]),
+				strconv.Itoa(all.Paired[contributor.Name]), formatShare(all.Rate(contributor.Name))})
+		}
+
+		ioutil.WriteFile("work/pairing_"+safeFileName(window.Name)+".dot", []byte(PairingGraph(setting, all)), 0644)
+	}
+	pairsWriter.Flush()
+	ratesWriter.Flush()
+
+	ioutil.WriteFile("work/pairing.csv", pairsBuffer.Bytes(), 0644)
+	ioutil.WriteFile("work/pairing_rate.csv", ratesBuffer.Bytes(), 0644)
+}
+
+// PairingGraph renders the pairing as an undirected Graphviz graph. Each
+// pair is one edge labelled with its commits; configured contributors are
+// drawn filled.
+func PairingGraph(setting Setting, pairing Pairing) string {
+	var buffer bytes.Buffer
+	buffer.WriteString("graph pairing {\n")
+
+	for _, contributor := range setting.Contributors {
+		fmt.Fprintf(&buffer, "  %q [style=filled];\n", contributor.Name)
+	}
+
+	var edges map[string]bool = make(map[string]bool)
+	for _, contributor := range setting.Contributors {
+		var partners map[string]int = pairing.Pairs[contributor.Name]
+		for _, partner := range sortedByCount(partners) {
+			var first, second string = contributor.Name, partner
+			if second < first {
+				first, second = second, first
+			}
+			if edges[first+"\n"+second] {
+				continue
+			}
+			edges[first+"\n"+second] = true
+			fmt.Fprintf(&buffer, "  %q -- %q [label=%d];\n", first, second, partners[partner])
+		}
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.String()
+}
+
+var unsafeFileCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func safeFileName(name string) string {
+	return unsafeFileCharacters.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_pairing_commits = `
+commit a39b69d7e6ab6c59c76102136815c6b7ae578804
+Author: Chris Piraino and Yu Zhang <cpiraino@pivotal.io>
+Date:   Tue Dec 29 17:56:22 2015 +0100
+
+    Add instructions to run tests
+
+commit 4d4033620e0c7280c8354504358a17b510c32e3f
+Author: Yu Zhang <yu.zhang@emc.com>
+Date:   Mon Dec 28 17:02:41 2015 +0100
+
+    Remove space
+
+    Signed-off-by: Victor Fong <victor.fong@emc.com>
+
+commit d89a0dc09f0a9948e02cc47220e0db2967e3cc7e
+Author: Yu Zhang <yu.zhang@emc.com>
+Date:   Mon Dec 28 16:56:56 2015 +0100
+
+    Solo commit
+`
+
+func TestPairing(t *testing.T) {
+	var setting Setting = Setting{Contributors: []Contributor{{Name: "Yu Zhang"}, {Name: "Victor Fong"}}}
+	scanner := bufio.NewScanner(strings.NewReader(test_pairing_commits))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+
+	var pairing Pairing = NewPairing()
+	pairing.Add(setting, commits)
+
+	assert.Equal(t, map[string]int{"Chris Piraino": 1, "Victor Fong": 1}, pairing.Pairs["Yu Zhang"])
+	assert.Equal(t, map[string]int{"Yu Zhang": 1}, pairing.Pairs["Victor Fong"])
+	assert.Equal(t, 3, pairing.Commits["Yu Zhang"])
+	assert.InDelta(t, 66.7, pairing.Rate("Yu Zhang"), 0.1)
+	assert.Equal(t, 100.0, pairing.Rate("Victor Fong"))
+	assert.Equal(t, 0.0, pairing.Rate("Nobody"))
+}
+
+func TestPairingByRepo(t *testing.T) {
+	var setting Setting = Setting{Contributors: []Contributor{{Name: "Yu Zhang"}}}
+	scanner := bufio.NewScanner(strings.NewReader(test_pairing_commits))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+	var history map[string][]GitCommit = map[string][]GitCommit{"repo1": commits, "repo2": commits[:1]}
+
+	var result map[string]Pairing = PairingByRepo(setting, history, Window{Begin: "2015-12-28", End: "2016-01-01"})
+	assert.Equal(t, 1, result["repo1"].Pairs["Yu Zhang"]["Chris Piraino"])
+	assert.Equal(t, 2, result[""].Pairs["Yu Zhang"]["Chris Piraino"])
+	assert.Equal(t, 0, result[""].Pairs["Yu Zhang"]["Victor Fong"])
+}
+
+func TestPairingGraph(t *testing.T) {
+	var setting Setting = Setting{Contributors: []Contributor{{Name: "Yu Zhang"}, {Name: "Victor Fong"}}}
+	scanner := bufio.NewScanner(strings.NewReader(test_pairing_commits))
+	var pairing Pairing = NewPairing()
+	pairing.Add(setting, ReadCommit(scanner, "repo1"))
+
+	assert.Equal(t, `graph pairing {
+  "Yu Zhang" [style=filled];
+  "Victor Fong" [style=filled];
+  "Chris Piraino" -- "Yu Zhang" [label=1];
+  "Victor Fong" -- "Yu Zhang" [label=1];
+}
+`, PairingGraph(setting, pairing))
+	assert.Equal(t, "2015_Q4", safeFileName("2015 Q4"))
+}
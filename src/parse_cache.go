@@ -0,0 +1,91 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Bump whenever GitCommit or the log format changes so that stale cache
+// files are ignored instead of being decoded into the wrong shape.
+const cacheVersion = "4"
+
+var cacheDir string = "work/cache"
+
+type CacheEntry struct {
+	// cacheVersion of the writer, checked where the key is not
+	Version string
+	Key     string
+	Commits []GitCommit
+}
+
+// The key covers every ref SHA of the repository together with the git log
+// arguments, so a fetch that moves any branch or tag invalidates the entry.
+func CacheKey(refs string, logArgs []string) string {
+	hash := sha1.New()
+	hash.Write([]byte(cacheVersion + "\n"))
+	hash.Write([]byte(strings.Join(logArgs, " ") + "\n"))
+	hash.Write([]byte(refs))
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func cacheFilePath(dir string, repoName string) string {
+	return filepath.Join(dir, repoName+".json")
+}
+
+func readCacheEntry(dir string, repoName string) (CacheEntry, bool) {
+	var entry CacheEntry
+
+	dat, err := ioutil.ReadFile(cacheFilePath(dir, repoName))
+	if err != nil {
+		return entry, false
+	}
+	if err := json.Unmarshal(dat, &entry); err != nil {
+		return entry, false
+	}
+
+	return entry, true
+}
+
+func ReadCache(dir string, repoName string, key string) ([]GitCommit, bool) {
+	entry, ok := readCacheEntry(dir, repoName)
+	if !ok || entry.Key != key {
+		return nil, false
+	}
+
+	return entry.Commits, true
+}
+
+// ReadAnyCache returns whatever was cached last for the repository, without
+// checking it against the current refs. Used when running offline. Entries
+// written in another format are still rejected.
+func ReadAnyCache(dir string, repoName string) ([]GitCommit, bool) {
+	entry, ok := readCacheEntry(dir, repoName)
+	if !ok {
+		return nil, false
+	}
+	if entry.Version != cacheVersion {
+		fmt.Printf("WARNING: ignoring cached commits for %s written by another version\n", repoName)
+		return nil, false
+	}
+
+	return entry.Commits, true
+}
+
+func WriteCache(dir string, repoName string, key string, commits []GitCommit) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	dat, err := json.Marshal(CacheEntry{Version: cacheVersion, Key: key, Commits: commits})
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(cacheFilePath(dir, repoName), dat, 0644)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRefs = `a39b69d7e6ab6c59c76102136815c6b7ae578804 HEAD
+a39b69d7e6ab6c59c76102136815c6b7ae578804 refs/heads/master
+`
+
+func TestCacheKey_ChangesWithRefs(t *testing.T) {
+	var key string = CacheKey(testRefs, []string{"--all"})
+
+	assert.Equal(t, key, CacheKey(testRefs, []string{"--all"}))
+	assert.NotEqual(t, key, CacheKey(testRefs+"7ce9e8b628034446c28b4955863386fbf4aa8c1d refs/tags/v1\n", []string{"--all"}))
+	assert.NotEqual(t, key, CacheKey(testRefs, []string{"HEAD"}))
+}
+
+func TestCache_RoundTrip(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "commit-count-cache")
+	defer os.RemoveAll(dir)
+
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var gitCommits []GitCommit = ReadCommit(scanner, "repo1")
+	var key string = CacheKey(testRefs, []string{"--all"})
+
+	_, ok := ReadCache(dir, "repo1", key)
+	assert.False(t, ok)
+
+	assert.Nil(t, WriteCache(dir, "repo1", key, gitCommits))
+
+	cached, ok := ReadCache(dir, "repo1", key)
+	assert.True(t, ok)
+	assert.Equal(t, len(gitCommits), len(cached))
+	assert.Equal(t, gitCommits[4].CoAuthor, cached[4].CoAuthor)
+	assert.True(t, gitCommits[0].Date.Equal(cached[0].Date))
+
+	_, ok = ReadCache(dir, "repo1", CacheKey("", []string{"--all"}))
+	assert.False(t, ok)
+}
+
+func TestReadAnyCache_RejectsOtherVersion(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "commit-count-cache")
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, WriteCache(dir, "repo1", "key", []GitCommit{{Author: "Victor Fong"}}))
+	cached, ok := ReadAnyCache(dir, "repo1")
+	assert.True(t, ok)
+	assert.Equal(t, "Victor Fong", cached[0].Author)
+
+	// Entries from before the version was stored, e.g. with dates truncated
+	// to the day
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "repo1.json"),
+		[]byte(`{"Key":"key","Commits":[{"Author":"Victor Fong","Date":"2015-10-15T00:00:00Z"}]}`), 0644))
+	_, ok = ReadAnyCache(dir, "repo1")
+	assert.False(t, ok)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const parseUsage = "usage: commit-count parse <log file|-> [repo name]"
+
+// RunParse parses git log output from a file, or stdin for "-", and prints
+// the commits as CSV. With a repo name the commits are also stored in the
+// parse cache, where --offline runs pick them up.
+func RunParse(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New(parseUsage)
+	}
+
+	var repoName string = "stdin"
+	if len(args) == 2 {
+		repoName = args[1]
+	}
+
+	var input io.Reader = stdin
+	if args[0] != "-" {
+		inFile, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer inFile.Close()
+		input = inFile
+	}
+
+	scanner := bufio.NewScanner(input)
+	var commits []GitCommit = ReadCommit(scanner, repoName)
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if len(args) == 2 {
+		if err := WriteCache(cacheDir, repoName, "", commits); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Cached %d commits for %s\n", len(commits), repoName)
+	}
+
+	return WriteCommitsCsv(stdout, commits)
+}
+
+func WriteCommitsCsv(out io.Writer, commits []GitCommit) error {
+	writer := csv.NewWriter(out)
+	writer.Write([]string{"Date", "Author", "Author Domain", "CoAuthor", "CoAuthor Domain", "Code Repo", "Files", "Commit Description"})
+	for _, commit := range commits {
+		writer.Write([]string{
+			commit.Date.Format(time.RFC3339),
+			commit.Author,
+			commit.AuthorDomain,
+			commit.CoAuthor,
+			commit.CoAuthorDomain,
+			commit.Repo,
+			strconv.Itoa(len(commit.Files)),
+			commit.Description,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
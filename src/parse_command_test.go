@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunParse_Stdin(t *testing.T) {
+	var out bytes.Buffer
+	err := RunParse([]string{"-"}, strings.NewReader(testNumstatCommit), &out)
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Equal(t, "Date,Author,Author Domain,CoAuthor,CoAuthor Domain,Code Repo,Files,Commit Description", lines[0])
+	assert.Equal(t, "2015-12-29T17:56:22+01:00,Marco Voelz,sap.com,Felix Riegger,sap.com,stdin,2,Add director spec", lines[1])
+}
+
+func TestRunParse_StoresCache(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "commit-count-parse")
+	defer os.RemoveAll(dir)
+	defer func(old string) { cacheDir = old }(cacheDir)
+	cacheDir = dir
+
+	var out bytes.Buffer
+	assert.Nil(t, RunParse([]string{"-", "Bosh"}, strings.NewReader(testNumstatCommit), &out))
+
+	commits, ok := ReadAnyCache(dir, "Bosh")
+	assert.True(t, ok)
+	assert.Equal(t, 3, len(commits))
+	assert.Equal(t, "Bosh", commits[0].Repo)
+}
+
+func TestRunParse_Usage(t *testing.T) {
+	var out bytes.Buffer
+	assert.NotNil(t, RunParse(nil, strings.NewReader(""), &out))
+}
+
+func TestLoadOfflineCommits(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "commit-count-offline")
+	defer os.RemoveAll(dir)
+	defer func(old string) { cacheDir = old }(cacheDir)
+	cacheDir = dir
+
+	var options RunOptions = RunOptions{UseCache: true, Offline: true}
+	assert.Nil(t, historyOf(Repository{Name: "Bosh", Url: "some_url"}, options))
+
+	var out bytes.Buffer
+	RunParse([]string{"-", "Bosh"}, strings.NewReader(testNumstatCommit), &out)
+	assert.Equal(t, 3, len(historyOf(Repository{Name: "Bosh", Url: "some_url"}, options)))
+}
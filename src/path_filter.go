@@ -0,0 +1,119 @@
+package main
+
+import (
+	"path"
+	"strings"
+)
+
+// Project is a virtual sub-project of a monorepo. It gets its own column in
+// result.csv and only counts commits touching its paths.
+type Project struct {
+	Name    string
+	Include []string
+	Exclude []string
+}
+
+// MatchPathGlob matches a slash separated path against a glob in which
+// "**" stands for any number of directories, e.g. "src/**/*_test.go".
+func MatchPathGlob(pattern string, filePath string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(filePath, "/"))
+}
+
+func matchSegments(pattern []string, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+func matchAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if MatchPathGlob(pattern, filePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// IncludesPath reports whether the file is included, or there is no
+// include, and not excluded.
+func IncludesPath(filePath string, include []string, exclude []string) bool {
+	return (len(include) == 0 || matchAny(include, filePath)) && !matchAny(exclude, filePath)
+}
+
+// TouchesPaths reports whether any file of the commit is included and not
+// excluded. Commits without a file list, such as merges, never match.
+func TouchesPaths(commit GitCommit, include []string, exclude []string) bool {
+	for _, file := range commit.Files {
+		if IncludesPath(file.Path, include, exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+func FilterCommitsByPath(commits []GitCommit, include []string, exclude []string) []GitCommit {
+	if len(include) == 0 && len(exclude) == 0 {
+		return commits
+	}
+
+	var result []GitCommit
+	for _, commit := range commits {
+		if TouchesPaths(commit, include, exclude) {
+			result = append(result, commit)
+		}
+	}
+	return result
+}
+
+// ReportProjects lists the result.csv columns of a repository: the
+// repository itself, or one entry per configured sub-project.
+func ReportProjects(repo Repository) []Project {
+	if len(repo.Projects) == 0 {
+		return []Project{{Name: repo.Name}}
+	}
+	return repo.Projects
+}
+
+// SplitProjects applies the repository's own path filters and then each
+// sub-project's, relabelling the commits with the sub-project name.
+func SplitProjects(repo Repository, commits []GitCommit) map[string][]GitCommit {
+	var result map[string][]GitCommit = make(map[string][]GitCommit)
+	var repoCommits []GitCommit = FilterCommitsByPath(commits, repo.Include, repo.Exclude)
+
+	for _, project := range ReportProjects(repo) {
+		var projectCommits []GitCommit
+		for _, commit := range FilterCommitsByPath(repoCommits, project.Include, project.Exclude) {
+			commit.Repo = project.Name
+			projectCommits = append(projectCommits, commit)
+		}
+		result[project.Name] = projectCommits
+	}
+	return result
+}
+
+func ReportColumns(setting Setting) []string {
+	var result []string
+	for _, repo := range setting.Repositories {
+		for _, project := range ReportProjects(repo) {
+			result = append(result, project.Name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testNumstatCommit = `
+commit a39b69d7e6ab6c59c76102136815c6b7ae578804
+Author: Marco Voelz <marco.voelz@sap.com>
+Date:   Tue Dec 29 17:56:22 2015 +0100
+
+    Add director spec
+
+    Signed-off-by: Felix Riegger <felix.riegger@sap.com>
+
+12	0	src/bosh-director/spec/unit/director_spec.rb
+3	1	src/bosh-director/lib/director.rb
+
+commit 4d4033620e0c7280c8354504358a17b510c32e3f
+Author: Marco Voelz <marco.voelz@sap.com>
+Date:   Mon Dec 28 17:02:41 2015 +0100
+
+    Move agent client
+
+5	5	src/{bosh-monitor => bosh-director}/lib/agent_client.rb
+
+commit d89a0dc09f0a9948e02cc47220e0db2967e3cc7e
+Author: Beyhan Veli <beyhan.veli@sap.com>
+Date:   Mon Dec 28 16:56:56 2015 +0100
+
+    Update docs
+
+1	1	docs/README.md
+-	-	docs/logo.png
+`
+
+func readNumstatCommits() []GitCommit {
+	scanner := bufio.NewScanner(strings.NewReader(testNumstatCommit))
+	return ReadCommit(scanner, "Bosh")
+}
+
+func TestReadCommit_Numstat(t *testing.T) {
+	var gitCommits []GitCommit = readNumstatCommits()
+	assert.Equal(t, 3, len(gitCommits))
+
+	assert.Equal(t, "Add director spec", gitCommits[0].Description)
+	assert.Equal(t, "Felix Riegger", gitCommits[0].CoAuthor)
+	assert.Equal(t, 2, len(gitCommits[0].Files))
+	assert.Equal(t, FileChange{Path: "src/bosh-director/lib/director.rb", Added: 3, Deleted: 1}, gitCommits[0].Files[1])
+
+	assert.Equal(t, "Move agent client", gitCommits[1].Description)
+	assert.Equal(t, "src/bosh-director/lib/agent_client.rb", gitCommits[1].Files[0].Path)
+
+	assert.Equal(t, FileChange{Path: "docs/logo.png"}, gitCommits[2].Files[1])
+}
+
+func TestMatchPathGlob(t *testing.T) {
+	assert.True(t, MatchPathGlob("src/bosh-director/**", "src/bosh-director/lib/director.rb"))
+	assert.True(t, MatchPathGlob("**/*_test.go", "main_test.go"))
+	assert.True(t, MatchPathGlob("**/*_test.go", "src/a/main_test.go"))
+	assert.True(t, MatchPathGlob("docs/*.md", "docs/README.md"))
+	assert.False(t, MatchPathGlob("docs/*.md", "docs/a/README.md"))
+	assert.False(t, MatchPathGlob("src/bosh-director/**", "src/bosh-monitor/lib/a.rb"))
+}
+
+func TestFilterCommitsByPath(t *testing.T) {
+	var gitCommits []GitCommit = readNumstatCommits()
+
+	assert.Equal(t, 3, len(FilterCommitsByPath(gitCommits, nil, nil)))
+	assert.Equal(t, 2, len(FilterCommitsByPath(gitCommits, []string{"src/bosh-director/**"}, nil)))
+	assert.Equal(t, 1, len(FilterCommitsByPath(gitCommits, []string{"src/bosh-director/**"}, []string{"**/spec/**", "**/agent_client.rb"})))
+	assert.Equal(t, 2, len(FilterCommitsByPath(gitCommits, nil, []string{"docs/**"})))
+}
+
+func TestIncludesPath(t *testing.T) {
+	assert.True(t, IncludesPath("docs/README.md", nil, nil))
+	assert.True(t, IncludesPath("src/bosh-director/lib/a.rb", []string{"src/bosh-director/**"}, nil))
+	assert.False(t, IncludesPath("src/bosh-agent/a.go", []string{"src/bosh-director/**"}, nil))
+	assert.False(t, IncludesPath("src/bosh-director/spec/a_spec.rb", []string{"src/bosh-director/**"}, []string{"**/spec/**"}))
+}
+
+var test_projects_data = `
+---
+repositories:
+- name: Bosh
+  url: some_url
+  exclude:
+  - docs/**
+  projects:
+  - name: Bosh_Director
+    include:
+    - src/bosh-director/**
+  - name: Bosh_Director_Specs
+    include:
+    - "**/spec/**"
+- name: UAA
+  url: some_url2
+contributors:
+- name: Marco Voelz
+`
+
+func TestSplitProjects(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_projects_data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"Bosh_Director", "Bosh_Director_Specs", "UAA"}, ReportColumns(setting))
+
+	var result map[string][]GitCommit = SplitProjects(setting.Repositories[0], readNumstatCommits())
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 2, len(result["Bosh_Director"]))
+	assert.Equal(t, "Bosh_Director", result["Bosh_Director"][0].Repo)
+	assert.Equal(t, 1, len(result["Bosh_Director_Specs"]))
+
+	result = SplitProjects(setting.Repositories[1], readNumstatCommits())
+	assert.Equal(t, 3, len(result["UAA"]))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"time"
+)
+
+// Policy is a rule checked on every run, e.g. "EMC has at least 10% of the
+// Bosh commits in 2016Q1" or "no more than 30 days without a commit to
+// CF_UAA". Commits are those of Organization or, without one, of configured
+// contributors, in Repository, in the members of Group or, with neither, in
+// every repository. Rules left at zero are not checked.
+type Policy struct {
+	Name         string
+	Repository   string
+	Group        string
+	Organization string
+	// The window of min_share and min_commits, by default the first one
+	Window      string
+	MinShare    float64 `yaml:"min_share"`
+	MinCommits  int     `yaml:"min_commits"`
+	MaxIdleDays int     `yaml:"max_idle_days"`
+}
+
+// Violation is one broken rule, as written to work/violations.json.
+type Violation struct {
+	Policy   string
+	Rule     string
+	Expected string
+	Actual   string
+	Message  string
+}
+
+func validatePolicies(setting Setting) error {
+	for _, policy := range setting.Policies {
+		if policy.Name == "" {
+			return fmt.Errorf("policy without a name")
+		}
+		if policy.Repository != "" && policy.Group != "" {
+			return fmt.Errorf("policy %s: set at most one of repository and group", policy.Name)
+		}
+		if policy.MinShare == 0 && policy.MinCommits == 0 && policy.MaxIdleDays == 0 {
+			return fmt.Errorf("policy %s: no rule, set min_share, min_commits or max_idle_days", policy.Name)
+		}
+		if policy.Window != "" {
+			if _, ok := findWindow(setting.Windows, policy.Window); !ok {
+				return fmt.Errorf("policy %s: no window %s", policy.Name, policy.Window)
+			}
+		}
+		if err := setting.validateScope(policy.Repository, policy.Group, ""); err != nil {
+			return fmt.Errorf("policy %s: %s", policy.Name, err)
+		}
+	}
+	return nil
+}
+
+func (policy Policy) window(setting Setting) Window {
+	if window, ok := findWindow(setting.Windows, policy.Window); ok {
+		return window
+	}
+	return setting.Windows[0]
+}
+
+func (policy Policy) owner() string {
+	if policy.Organization != "" {
+		return policy.Organization
+	}
+	return "contributors"
+}
+
+func (policy Policy) scope() string {
+	if policy.Repository != "" {
+		return policy.Repository
+	}
+	if policy.Group != "" {
+		return policy.Group
+	}
+	return "all repositories"
+}
+
+func (policy Policy) isOwnCommit(setting Setting, commit GitCommit) bool {
+	if policy.Organization != "" {
+		return OrganizationOfDomain(setting, commit.AuthorDomain) == policy.Organization
+	}
+	return len(CommitContributors(commit, setting.Contributors)) > 0
+}
+
+// EvaluatePolicy checks every rule of the policy against the history, keyed
+// by repo name. now is the reference for max_idle_days.
+func EvaluatePolicy(setting Setting, policy Policy, history map[string][]GitCommit, now time.Time) []Violation {
+	var result []Violation
+	var window Window = policy.window(setting)
+
+	var total, own int = 0, 0
+	var last time.Time
+	for _, repoName := range scopeRepositories(setting, policy.Repository, policy.Group, history) {
+		for _, commit := range history[repoName] {
+			if policy.isOwnCommit(setting, commit) && commit.Date.After(last) {
+				last = commit.Date
+			}
+		}
+		for _, commit := range CommitsInWindow(history[repoName], window) {
+			total++
+			if policy.isOwnCommit(setting, commit) {
+				own++
+			}
+		}
+	}
+
+	if policy.MinShare > 0 {
+		var share float64 = 0
+		if total > 0 {
+			share = 100 * float64(own) / float64(total)
+		}
+		if share < policy.MinShare {
+			result = append(result, Violation{policy.Name, "min_share", formatShare(policy.MinShare), formatShare(share),
+				fmt.Sprintf("%s have %s%% of the %s commits in %s, less than %s%%",
+					policy.owner(), formatShare(share), policy.scope(), window.Name, formatShare(policy.MinShare))})
+		}
+	}
+
+	if policy.MinCommits > 0 && own < policy.MinCommits {
+		result = append(result, Violation{policy.Name, "min_commits", strconv.Itoa(policy.MinCommits), strconv.Itoa(own),
+			fmt.Sprintf("%s have %d commits to %s in %s, less than %d",
+				policy.owner(), own, policy.scope(), window.Name, policy.MinCommits)})
+	}
+
+	if policy.MaxIdleDays > 0 {
+		if last.IsZero() {
+			result = append(result, Violation{policy.Name, "max_idle_days", strconv.Itoa(policy.MaxIdleDays), "never",
+				fmt.Sprintf("%s never committed to %s", policy.owner(), policy.scope())})
+		} else if idle := int(dayOf(now).Sub(dayOf(last)).Hours() / 24); idle > policy.MaxIdleDays {
+			result = append(result, Violation{policy.Name, "max_idle_days", strconv.Itoa(policy.MaxIdleDays), strconv.Itoa(idle),
+				fmt.Sprintf("%s last committed to %s %d days ago, more than %d",
+					policy.owner(), policy.scope(), idle, policy.MaxIdleDays)})
+		}
+	}
+	return result
+}
+
+func EvaluatePolicies(setting Setting, history map[string][]GitCommit, now time.Time) []Violation {
+	var result []Violation = []Violation{}
+	for _, policy := range setting.Policies {
+		result = append(result, EvaluatePolicy(setting, policy, history, now)...)
+	}
+	return result
+}
+
+// CreateViolationOutputFile writes the violations to work/violations.json,
+// an empty list when every policy holds.
+func CreateViolationOutputFile(violations []Violation) {
+	for _, violation := range violations {
+		fmt.Printf("POLICY VIOLATION: %s: %s\n", violation.Policy, violation.Message)
+	}
+
+	dat, err := json.MarshalIndent(violations, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	ioutil.WriteFile("work/violations.json", append(dat, '\n'), 0644)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_policies_data = `
+---
+windows:
+- name: 2015Q4
+  begin: "2015-09-30"
+  end: "2016-01-01"
+repositories:
+- name: Bosh
+  url: https://github.com/cloudfoundry/bosh.git
+- name: CF_UAA
+  url: https://github.com/cloudfoundry/uaa.git
+contributors:
+- name: Victor Fong
+organizations:
+- name: EMC
+  domains: [emc.com]
+policies:
+- name: bosh-share
+  repository: Bosh
+  organization: EMC
+  min_share: 50
+- name: bosh-commits
+  repository: Bosh
+  min_commits: 1
+- name: uaa-activity
+  repository: CF_UAA
+  max_idle_days: 30
+`
+
+var test_policies_history = map[string][]GitCommit{
+	"Bosh": {
+		{Author: "Victor Fong", AuthorDomain: "emc.com", Date: time.Date(2015, 10, 16, 0, 0, 0, 0, time.UTC)},
+		{Author: "Chris Piraino", AuthorDomain: "pivotal.io", Date: time.Date(2015, 11, 2, 0, 0, 0, 0, time.UTC)},
+		{Author: "Yu Zhang", AuthorDomain: "pivotal.io", Date: time.Date(2015, 11, 3, 0, 0, 0, 0, time.UTC)},
+	},
+	"CF_UAA": {
+		{Author: "Victor Fong", AuthorDomain: "emc.com", Date: time.Date(2015, 12, 1, 0, 0, 0, 0, time.UTC)},
+	},
+}
+
+func TestUnmarshalYaml_Policies(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_policies_data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(setting.Policies))
+	assert.Equal(t, 50.0, setting.Policies[0].MinShare)
+
+	_, err = UnmarshalYaml([]byte("policies:\n- name: empty\n  repository: Bosh\n"))
+	assert.NotNil(t, err)
+
+	_, err = UnmarshalYaml([]byte("policies:\n- name: share\n  min_share: 10\n  window: 2015Q1\n"))
+	assert.NotNil(t, err)
+
+	// A typo would otherwise be a permanent "never committed" violation
+	_, err = UnmarshalYaml([]byte(strings.Replace(test_policies_data, "repository: CF_UAA", "repository: CF-UAA", 1)))
+	assert.NotNil(t, err)
+
+	_, err = UnmarshalYaml([]byte("policies:\n- name: bosh\n  group: BOSH\n  min_commits: 1\n"))
+	assert.NotNil(t, err)
+}
+
+func TestEvaluatePolicies(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_policies_data))
+
+	var violations []Violation = EvaluatePolicies(setting, test_policies_history, time.Date(2015, 12, 20, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 1, len(violations))
+	assert.Equal(t, "bosh-share", violations[0].Policy)
+	assert.Equal(t, "min_share", violations[0].Rule)
+	assert.Equal(t, "50.0", violations[0].Expected)
+	assert.Equal(t, "33.3", violations[0].Actual)
+
+	violations = EvaluatePolicies(setting, test_policies_history, time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, 2, len(violations))
+	assert.Equal(t, Violation{"uaa-activity", "max_idle_days", "30", "35",
+		"contributors last committed to CF_UAA 35 days ago, more than 30"}, violations[1])
+}
+
+func TestEvaluatePolicy_Never(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_policies_data))
+	var policy Policy = Policy{Name: "diego", Repository: "Diego", MinCommits: 1, MaxIdleDays: 30}
+
+	var violations []Violation = EvaluatePolicy(setting, policy, test_policies_history, time.Now())
+	assert.Equal(t, 2, len(violations))
+	assert.Equal(t, "0", violations[0].Actual)
+	assert.Equal(t, "never", violations[1].Actual)
+}
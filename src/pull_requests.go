@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+var pullRequestPattern = regexp.MustCompile(`^Merge pull request #(\d+) from ([^/\s]+)/(\S+)`)
+
+// ParsePullRequestMerge extracts the number, fork owner and branch from the
+// description of a GitHub merge commit.
+func ParsePullRequestMerge(description string) (int, string, string, bool) {
+	var match []string = pullRequestPattern.FindStringSubmatch(description)
+	if match == nil {
+		return 0, "", "", false
+	}
+
+	number, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, "", "", false
+	}
+	return number, match[2], match[3], true
+}
+
+type PullRequestCounts struct {
+	ByRepo         map[string]int
+	ByOwner        map[string]int
+	ByOrganization map[string]int
+}
+
+// CountPullRequests counts merged pull requests in the history of every
+// repository. Each pull request number is counted once per repository.
+// history must not be filtered by path: merge commits carry no file list,
+// so path filters drop them.
+func CountPullRequests(setting Setting, history map[string][]GitCommit) PullRequestCounts {
+	var result PullRequestCounts = PullRequestCounts{
+		ByRepo:         make(map[string]int),
+		ByOwner:        make(map[string]int),
+		ByOrganization: make(map[string]int),
+	}
+
+	for repoName, commits := range history {
+		var seen map[int]bool = make(map[int]bool)
+		for _, commit := range commits {
+			if commit.PullRequest == 0 || seen[commit.PullRequest] {
+				continue
+			}
+			seen[commit.PullRequest] = true
+
+			result.ByRepo[repoName]++
+			result.ByOwner[commit.PullRequestOwner]++
+			result.ByOrganization[OrganizationOfOwner(setting, commit.PullRequestOwner)]++
+		}
+	}
+	return result
+}
+
+// sortedByCount returns the keys of counts, largest count first.
+func sortedByCount(counts map[string]int) []string {
+	var keys []string
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+func CreatePullRequestOutputFile(counts PullRequestCounts) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Dimension", "Name", "Merged Pull Requests"})
+	for _, dimension := range []struct {
+		name   string
+		counts map[string]int
+	}{
+		{"repo", counts.ByRepo},
+		{"owner", counts.ByOwner},
+		{"organization", counts.ByOrganization},
+	} {
+		for _, key := range sortedByCount(dimension.counts) {
+			writer.Write([]string{dimension.name, key, strconv.Itoa(dimension.counts[key])})
+		}
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/pull_requests.csv", buffer.Bytes(), 0644)
+}
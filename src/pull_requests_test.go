@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePullRequestMerge(t *testing.T) {
+	number, owner, branch, ok := ParsePullRequestMerge("Merge pull request #17 from hashmap/power-builder Enable ppc64le support")
+	assert.True(t, ok)
+	assert.Equal(t, 17, number)
+	assert.Equal(t, "hashmap", owner)
+	assert.Equal(t, "power-builder", branch)
+
+	_, _, branch, _ = ParsePullRequestMerge("Merge pull request #3 from cloudfoundry/feature/ipv6")
+	assert.Equal(t, "feature/ipv6", branch)
+
+	_, _, _, ok = ParsePullRequestMerge("Merge branch 'master' into hotfix-postgres")
+	assert.False(t, ok)
+}
+
+func TestReadCommit_PullRequest(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var gitCommits []GitCommit = ReadCommit(scanner, "repo1")
+
+	assert.Equal(t, 0, gitCommits[0].PullRequest)
+	assert.Equal(t, 17, gitCommits[3].PullRequest)
+	assert.Equal(t, "hashmap", gitCommits[3].PullRequestOwner)
+	assert.Equal(t, "power-builder", gitCommits[3].PullRequestBranch)
+}
+
+var test_organizations_data = `
+---
+organizations:
+- name: IBM
+  domains:
+  - ibm.com
+  owners:
+  - HashMap
+`
+
+func TestCountPullRequests(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_organizations_data))
+	var history map[string][]GitCommit = map[string][]GitCommit{
+		"Bosh": {
+			{PullRequest: 17, PullRequestOwner: "hashmap"},
+			{PullRequest: 17, PullRequestOwner: "hashmap"},
+			{PullRequest: 18, PullRequestOwner: "sap-cloudfoundry"},
+			{Description: "Update README.md"},
+		},
+		"UAA": {
+			{PullRequest: 17, PullRequestOwner: "hashmap"},
+		},
+	}
+
+	var counts PullRequestCounts = CountPullRequests(setting, history)
+	assert.Equal(t, 2, counts.ByRepo["Bosh"])
+	assert.Equal(t, 1, counts.ByRepo["UAA"])
+	assert.Equal(t, 2, counts.ByOwner["hashmap"])
+	assert.Equal(t, 2, counts.ByOrganization["IBM"])
+	assert.Equal(t, 1, counts.ByOrganization["Other"])
+	assert.Equal(t, []string{"hashmap", "sap-cloudfoundry"}, sortedByCount(counts.ByOwner))
+}
+
+func TestCountPullRequests_PathFiltered(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_organizations_data))
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var gitCommits []GitCommit = ReadCommit(scanner, "repo1")
+
+	// The merge of #17 has no file list, so an include filter drops it
+	var filtered []GitCommit = FilterCommitsByPath(gitCommits, []string{"src/**"}, nil)
+	assert.Equal(t, 0, CountPullRequests(setting, map[string][]GitCommit{"Bosh": filtered}).ByRepo["Bosh"])
+	assert.Equal(t, 1, CountPullRequests(setting, map[string][]GitCommit{"Bosh": gitCommits}).ByRepo["Bosh"])
+}
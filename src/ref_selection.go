@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// RefSelection decides which history of a repository is counted. Each entry
+// is one of:
+//
+//	default      the branch HEAD points at
+//	all          every ref, like git log --all
+//	v1.0..v2.0   a revision range, passed to git log as is
+//	release/*    a branch glob, matched against local and origin branches
+//
+// In setting.yml it can be written as a single string or as a list.
+type RefSelection []string
+
+func (r *RefSelection) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*r = RefSelection{single}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*r = RefSelection(list)
+	return nil
+}
+
+var defaultRefSelection RefSelection = RefSelection{"all"}
+
+// RefNames extracts the ref names from git show-ref output.
+func RefNames(showRef string) []string {
+	var result []string
+	for _, line := range strings.Split(showRef, "\n") {
+		elements := strings.Fields(line)
+		if len(elements) == 2 {
+			result = append(result, elements[1])
+		}
+	}
+	return result
+}
+
+func shortBranchName(refName string) (string, bool) {
+	for _, prefix := range []string{"refs/heads/", "refs/remotes/origin/"} {
+		if strings.HasPrefix(refName, prefix) {
+			return strings.TrimPrefix(refName, prefix), true
+		}
+	}
+	return "", false
+}
+
+// LogArgs turns a selection into git log revision arguments. An empty result
+// means nothing matched and the repository has no history to count.
+func LogArgs(selection RefSelection, refNames []string) []string {
+	if len(selection) == 0 {
+		selection = defaultRefSelection
+	}
+
+	var result []string
+	var seen map[string]bool = make(map[string]bool)
+	add := func(arg string) {
+		if !seen[arg] {
+			seen[arg] = true
+			result = append(result, arg)
+		}
+	}
+
+	for _, entry := range selection {
+		switch {
+		case entry == "default":
+			add("HEAD")
+		case entry == "all":
+			add("--all")
+		case strings.Contains(entry, ".."):
+			add(entry)
+		default:
+			var matched bool = false
+			for _, refName := range refNames {
+				branch, ok := shortBranchName(refName)
+				if !ok {
+					continue
+				}
+				if ok, _ := path.Match(entry, branch); ok {
+					add(refName)
+					matched = true
+				}
+			}
+			if !matched {
+				fmt.Printf("No branch matches %s\n", entry)
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRefNames = []string{
+	"HEAD",
+	"refs/heads/master",
+	"refs/remotes/origin/master",
+	"refs/remotes/origin/release/1.0",
+	"refs/remotes/origin/release/2.0",
+	"refs/remotes/origin/experiment",
+	"refs/tags/v1.0",
+}
+
+var test_refs_data = `
+---
+refs: default
+repositories:
+- name: Bosh
+  url: some_url
+  refs:
+  - master
+  - release/*
+- name: UAA
+  url: some_url2
+- name: CPI
+  url: some_url3
+  refs: v1.0..v2.0
+`
+
+func TestReadSetting_Refs(t *testing.T) {
+	result, err := UnmarshalYaml([]byte(test_refs_data))
+
+	assert.Equal(t, nil, err)
+	assert.Equal(t, RefSelection{"default"}, result.Refs)
+	assert.Equal(t, RefSelection{"master", "release/*"}, result.Repositories[0].Refs)
+	assert.Equal(t, RefSelection{"default"}, result.Repositories[1].Refs)
+	assert.Equal(t, RefSelection{"v1.0..v2.0"}, result.Repositories[2].Refs)
+}
+
+func TestReadSetting_DefaultRefsIsAll(t *testing.T) {
+	result, _ := UnmarshalYaml([]byte(test_data))
+
+	assert.Equal(t, RefSelection{"all"}, result.Refs)
+	assert.Equal(t, RefSelection{"all"}, result.Repositories[0].Refs)
+}
+
+func TestRefNames(t *testing.T) {
+	assert.Equal(t, []string{"HEAD", "refs/heads/master"}, RefNames(testRefs))
+}
+
+func TestLogArgs(t *testing.T) {
+	assert.Equal(t, []string{"--all"}, LogArgs(nil, testRefNames))
+	assert.Equal(t, []string{"HEAD"}, LogArgs(RefSelection{"default"}, testRefNames))
+	assert.Equal(t, []string{"v1.0..v2.0"}, LogArgs(RefSelection{"v1.0..v2.0"}, testRefNames))
+	assert.Equal(t,
+		[]string{"refs/heads/master", "refs/remotes/origin/master", "refs/remotes/origin/release/1.0", "refs/remotes/origin/release/2.0"},
+		LogArgs(RefSelection{"master", "release/*"}, testRefNames))
+	assert.Equal(t, 0, len(LogArgs(RefSelection{"feature/*"}, testRefNames)))
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReferenceExtractor finds issue tracker references in commit descriptions.
+// Pattern is a regular expression whose first group is the story or issue
+// id. Extractors sharing a name are one tracker, so the same id found twice
+// is only recorded once.
+type ReferenceExtractor struct {
+	Name    string
+	Pattern string
+}
+
+type Reference struct {
+	Tracker string
+	Id      string
+}
+
+// Used when setting.yml has no references section. JIRA keys are opt-in
+// through jira_projects, as a bare KEY-123 pattern also matches UTF-8,
+// SHA-256 or CVE-2016.
+var defaultReferenceExtractors []ReferenceExtractor = []ReferenceExtractor{
+	{Name: "tracker", Pattern: `(?i)\[(?:(?:start(?:s|ed)?|finish(?:es|ed)?|fix(?:es|ed)?|complete[sd]?|deliver(?:s|ed)?)\s+)?#(\d+)\]`},
+	{Name: "tracker", Pattern: `pivotaltracker\.com/(?:n/projects/\d+/)?stor(?:y/show|ies)/(\d+)`},
+	{Name: "github", Pattern: `(?i)(?:^|[^\[\w])(?:close[sd]?|fix(?:es|ed)?|resolve[sd]?)\s+#(\d+)`},
+}
+
+var jiraProjectKey *regexp.Regexp = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// jiraExtractor matches issue keys of the given JIRA projects only.
+func jiraExtractor(projects []string) (ReferenceExtractor, error) {
+	for _, project := range projects {
+		if !jiraProjectKey.MatchString(project) {
+			return ReferenceExtractor{}, fmt.Errorf("jira project %q is not a project key such as BOSH", project)
+		}
+	}
+	return ReferenceExtractor{Name: "jira", Pattern: `\b((?:` + strings.Join(projects, "|") + `)-[0-9]+)\b`}, nil
+}
+
+// referenceExtractors is the references section, or the defaults without
+// one, plus the JIRA extractor when jira_projects is set.
+func (setting Setting) referenceExtractors() ([]ReferenceExtractor, error) {
+	var result []ReferenceExtractor = setting.References
+	if len(result) == 0 {
+		result = defaultReferenceExtractors
+	}
+	if len(setting.JiraProjects) == 0 {
+		return result, nil
+	}
+
+	jira, err := jiraExtractor(setting.JiraProjects)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]ReferenceExtractor{}, result...), jira), nil
+}
+
+type compiledExtractor struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+func compileExtractors(extractors []ReferenceExtractor) ([]compiledExtractor, error) {
+	if len(extractors) == 0 {
+		extractors = defaultReferenceExtractors
+	}
+
+	var result []compiledExtractor
+	for _, extractor := range extractors {
+		pattern, err := regexp.Compile(extractor.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("reference %s: %s", extractor.Name, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return nil, fmt.Errorf("reference %s: pattern needs a group for the id", extractor.Name)
+		}
+		result = append(result, compiledExtractor{name: extractor.Name, pattern: pattern})
+	}
+	return result, nil
+}
+
+func extractReferences(description string, extractors []compiledExtractor) []Reference {
+	var result []Reference
+	for _, extractor := range extractors {
+		for _, match := range extractor.pattern.FindAllStringSubmatch(description, -1) {
+			var reference Reference = Reference{Tracker: extractor.name, Id: match[1]}
+			if !containsReference(result, reference) {
+				result = append(result, reference)
+			}
+		}
+	}
+	return result
+}
+
+func containsReference(references []Reference, reference Reference) bool {
+	for _, element := range references {
+		if element == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// AttachReferences sets References on every commit from its description.
+func AttachReferences(commits []GitCommit, extractors []ReferenceExtractor) ([]GitCommit, error) {
+	compiled, err := compileExtractors(extractors)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range commits {
+		commits[i].References = extractReferences(commits[i].Description, compiled)
+	}
+	return commits, nil
+}
+
+// CountReferences collects the distinct references delivered by each
+// configured contributor per repository: contributor -> repo -> references.
+func CountReferences(setting Setting, history map[string][]GitCommit) map[string]map[string][]Reference {
+	var result map[string]map[string][]Reference = make(map[string]map[string][]Reference)
+	for _, contributor := range setting.Contributors {
+		result[contributor.Name] = make(map[string][]Reference)
+	}
+
+	for repoName, commits := range history {
+		for _, commit := range commits {
+			isEmcCommit, contributorName := IsEmcCommit(commit, setting.Contributors)
+			if !isEmcCommit {
+				continue
+			}
+			for _, reference := range commit.References {
+				if !containsReference(result[contributorName][repoName], reference) {
+					result[contributorName][repoName] = append(result[contributorName][repoName], reference)
+				}
+			}
+		}
+	}
+	return result
+}
+
+func CreateReferenceOutputFile(setting Setting, result map[string]map[string][]Reference) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Contributor", "Code Repo", "Tracker", "Count", "Ids"})
+	for _, contributor := range setting.Contributors {
+		var repoNames []string
+		for repoName := range result[contributor.Name] {
+			repoNames = append(repoNames, repoName)
+		}
+		sort.Strings(repoNames)
+
+		for _, repoName := range repoNames {
+			var ids map[string][]string = make(map[string][]string)
+			var trackers []string
+			for _, reference := range result[contributor.Name][repoName] {
+				if _, ok := ids[reference.Tracker]; !ok {
+					trackers = append(trackers, reference.Tracker)
+				}
+				ids[reference.Tracker] = append(ids[reference.Tracker], reference.Id)
+			}
+
+			for _, tracker := range trackers {
+				writer.Write([]string{contributor.Name, repoName, tracker,
+					strconv.Itoa(len(ids[tracker])), strings.Join(ids[tracker], " ")})
+			}
+		}
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/references.csv", buffer.Bytes(), 0644)
+}
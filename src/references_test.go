@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachReferences_Defaults(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	gitCommits, err := AttachReferences(ReadCommit(scanner, "repo1"), nil)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 0, len(gitCommits[0].References))
+	assert.Equal(t, []Reference{{Tracker: "tracker", Id: "108602248"}}, gitCommits[4].References)
+}
+
+func TestAttachReferences_Patterns(t *testing.T) {
+	var gitCommits []GitCommit = []GitCommit{
+		{Description: "Fix flaky test [Finishes #108602248] Fixes #123, see BOSH-42"},
+		{Description: "Bump release [fixes #99] closes cloudfoundry/bosh#7"},
+	}
+	gitCommits, _ = AttachReferences(gitCommits, nil)
+
+	assert.Equal(t, []Reference{
+		{Tracker: "tracker", Id: "108602248"},
+		{Tracker: "github", Id: "123"},
+	}, gitCommits[0].References)
+	assert.Equal(t, []Reference{{Tracker: "tracker", Id: "99"}}, gitCommits[1].References)
+}
+
+func TestAttachReferences_JiraProjects(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte("jira_projects: [BOSH, UAA]\n"))
+	assert.Nil(t, err)
+
+	var gitCommits []GitCommit = enrichCommits(setting, []GitCommit{
+		{Description: "Fix flaky test [#108602248], see BOSH-42 and UAA-7"},
+		{Description: "Switch to UTF-8 and SHA-256, fix CVE-2016-1234 on ARM64-1"},
+	})
+	assert.Equal(t, []Reference{
+		{Tracker: "tracker", Id: "108602248"},
+		{Tracker: "jira", Id: "BOSH-42"},
+		{Tracker: "jira", Id: "UAA-7"},
+	}, gitCommits[0].References)
+	assert.Equal(t, 0, len(gitCommits[1].References))
+
+	gitCommits, _ = AttachReferences([]GitCommit{{Description: "Switch to UTF-8 and SHA-256, fix CVE-2016-1234 on ARM64-1"}}, nil)
+	assert.Equal(t, 0, len(gitCommits[0].References))
+
+	_, err = UnmarshalYaml([]byte("jira_projects: [\"BOSH|.*\"]\n"))
+	assert.NotNil(t, err)
+}
+
+var test_references_data = `
+---
+references:
+- name: bugzilla
+  pattern: 'bz(\d+)'
+contributors:
+- name: Beyhan Veli
+`
+
+func TestAttachReferences_Configured(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_references_data))
+	assert.Nil(t, err)
+
+	var gitCommits []GitCommit = enrichCommits(setting, []GitCommit{{Description: "Fix bz123 [#108602248]"}})
+	assert.Equal(t, []Reference{{Tracker: "bugzilla", Id: "123"}}, gitCommits[0].References)
+
+	_, err = UnmarshalYaml([]byte("references:\n- name: broken\n  pattern: 'no group'\n"))
+	assert.NotNil(t, err)
+}
+
+func TestCountReferences(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_references_data))
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = enrichCommits(Setting{}, ReadCommit(scanner, "repo1"))
+	commits = append(commits, commits[4])
+
+	var result map[string]map[string][]Reference = CountReferences(setting, map[string][]GitCommit{"repo1": commits})
+	assert.Equal(t, []Reference{{Tracker: "tracker", Id: "108602248"}}, result["Beyhan Veli"]["repo1"])
+}
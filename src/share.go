@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io/ioutil"
+	"sort"
+	"strconv"
+)
+
+// CountOrganizationShare counts the commits of a repository per
+// organization within the window, plus TOTAL. Domains map to organizations
+// through OrganizationOfDomain.
+func CountOrganizationShare(setting Setting, commits []GitCommit, window Window) map[string]int {
+	var domains map[string]int = make(map[string]int)
+	beginDate, endDate := window.Dates()
+	CountOverallCommit(commits, domains, beginDate, endDate)
+
+	var result map[string]int = make(map[string]int)
+	for domain, count := range domains {
+		if domain == "TOTAL" {
+			result["TOTAL"] += count
+		} else {
+			result[OrganizationOfDomain(setting, domain)] += count
+		}
+	}
+	return result
+}
+
+// CreateShareOutputFile writes window,repo,organization rows with the
+// organization's rank, commits and percentage of the repository's commits.
+// result is window -> repo -> organization -> count.
+func CreateShareOutputFile(setting Setting, result map[string]map[string]map[string]int) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Window", "Code Repo", "Organization", "Rank", "Commits", "Share"})
+	for _, window := range setting.Windows {
+		var repoNames []string
+		for repoName := range result[window.Name] {
+			repoNames = append(repoNames, repoName)
+		}
+		sort.Strings(repoNames)
+
+		for _, repoName := range repoNames {
+			var counts map[string]int = result[window.Name][repoName]
+			var total int = counts["TOTAL"]
+			if total == 0 {
+				continue
+			}
+
+			var rank int = 0
+			for _, organization := range sortedByCount(counts) {
+				if organization == "TOTAL" {
+					continue
+				}
+				rank++
+				var share float64 = 100 * float64(counts[organization]) / float64(total)
+				writer.Write([]string{window.Name, repoName, organization, strconv.Itoa(rank),
+					strconv.Itoa(counts[organization]), strconv.FormatFloat(share, 'f', 1, 64)})
+			}
+		}
+	}
+	writer.Flush()
+
+	ioutil.WriteFile("work/share.csv", buffer.Bytes(), 0644)
+}
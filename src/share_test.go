@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_windows_data = `
+---
+organizations:
+- name: SAP
+  domains:
+  - sap.com
+windows:
+- name: 2015Q4
+  begin: 2015-09-30
+  end: 2016-01-01
+- name: 2015H2
+  begin: "2015-06-30"
+  end: "2016-01-01"
+`
+
+func TestReadSetting_Windows(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_windows_data))
+	assert.Nil(t, err)
+	assert.Equal(t, []Window{{Name: "2015Q4", Begin: "2015-09-30", End: "2016-01-01"},
+		{Name: "2015H2", Begin: "2015-06-30", End: "2016-01-01"}}, setting.Windows)
+
+	setting, _ = UnmarshalYaml([]byte(test_data))
+	assert.Equal(t, defaultWindows, setting.Windows)
+
+	_, err = UnmarshalYaml([]byte("windows:\n- name: bad\n  begin: Q4\n  end: 2016-01-01\n"))
+	assert.NotNil(t, err)
+}
+
+func TestReadSetting_Timezone(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte("timezone: UTC\n"))
+	assert.Nil(t, err)
+	location, _ := setting.location()
+	assert.Equal(t, time.UTC, location)
+
+	setting, _ = UnmarshalYaml([]byte(test_data))
+	location, _ = setting.location()
+	assert.Nil(t, location)
+
+	_, err = UnmarshalYaml([]byte("timezone: Mars/Olympus_Mons\n"))
+	assert.NotNil(t, err)
+}
+
+func TestCountOrganizationShare(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_windows_data))
+	scanner := bufio.NewScanner(strings.NewReader(testCommit))
+	var commits []GitCommit = ReadCommit(scanner, "repo1")
+	commits = append(commits, GitCommit{AuthorDomain: "pivotal.io", Date: getDate("2015-12-01")})
+
+	var result map[string]int = CountOrganizationShare(setting, commits, setting.Windows[0])
+	assert.Equal(t, 7, result["TOTAL"])
+	assert.Equal(t, 6, result["SAP"])
+	assert.Equal(t, 1, result["pivotal.io"])
+
+	result = CountOrganizationShare(setting, commits, Window{Name: "old", Begin: "2014-01-01", End: "2015-01-01"})
+	assert.Equal(t, 0, result["TOTAL"])
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Diff configures commit-count diff. Organization shares of a repository
+// that moved by at least Threshold percentage points are reported.
+type Diff struct {
+	Threshold float64
+}
+
+const defaultShareThreshold = 5
+
+var snapshotDir string = "work/snapshots"
+
+// The outputs a snapshot keeps a copy of.
+var snapshotFiles []string = []string{"result.csv", "total_count.csv", "share.csv"}
+
+// SaveSnapshot copies the outputs of this run into a directory of
+// snapshotDir named after now to the millisecond, and returns that name. It
+// fails rather than overwrite the snapshot of another run.
+func SaveSnapshot(workDir string, dir string, now time.Time) (string, error) {
+	var name string = now.Format("20060102-150405.000")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+		return "", err
+	}
+
+	for _, file := range snapshotFiles {
+		dat, err := ioutil.ReadFile(filepath.Join(workDir, file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, name, file), dat, 0644); err != nil {
+			return "", err
+		}
+	}
+	return name, nil
+}
+
+// ListSnapshots returns the snapshot names, oldest first.
+func ListSnapshots(dir string) []string {
+	var result []string
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			result = append(result, entry.Name())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+type Snapshot struct {
+	// contributor -> repo -> commits, from result.csv
+	Commits map[string]map[string]int
+	// domain -> commits, from total_count.csv
+	Domains map[string]int
+	// "window/repo/organization" -> share, from share.csv
+	Shares map[string]float64
+}
+
+func readCsvFile(path string) [][]string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil
+	}
+	return records
+}
+
+// ReadSnapshot loads a snapshot directory. Files missing from it, e.g. from
+// runs before the file existed, read as empty.
+func ReadSnapshot(dir string) Snapshot {
+	var result Snapshot = Snapshot{
+		Commits: make(map[string]map[string]int),
+		Domains: make(map[string]int),
+		Shares:  make(map[string]float64),
+	}
+
+	var records [][]string = readCsvFile(filepath.Join(dir, "result.csv"))
+	for i := 1; i < len(records); i++ {
+		var contributor string = records[i][0]
+		result.Commits[contributor] = make(map[string]int)
+		for j := 1; j < len(records[i]) && j < len(records[0]); j++ {
+			count, _ := strconv.Atoi(records[i][j])
+			result.Commits[contributor][records[0][j]] = count
+		}
+	}
+
+	for _, record := range readCsvFile(filepath.Join(dir, "total_count.csv")) {
+		if len(record) == 2 {
+			count, _ := strconv.Atoi(record[1])
+			result.Domains[record[0]] = count
+		}
+	}
+
+	records = readCsvFile(filepath.Join(dir, "share.csv"))
+	for i := 1; i < len(records); i++ {
+		if len(records[i]) < 6 {
+			continue
+		}
+		share, _ := strconv.ParseFloat(records[i][5], 64)
+		result.Shares[records[i][0]+"/"+records[i][1]+"/"+records[i][2]] = share
+	}
+	return result
+}
+
+type DiffRow struct {
+	Section string
+	Name    string
+	Before  float64
+	After   float64
+}
+
+func (row DiffRow) Delta() float64 {
+	return row.After - row.Before
+}
+
+func (snapshot Snapshot) contributorTotals() map[string]int {
+	var result map[string]int = make(map[string]int)
+	for contributor, repos := range snapshot.Commits {
+		result[contributor] += 0
+		for _, count := range repos {
+			result[contributor] += count
+		}
+	}
+	return result
+}
+
+func (snapshot Snapshot) repoTotals() map[string]int {
+	var result map[string]int = make(map[string]int)
+	for _, repos := range snapshot.Commits {
+		for repo, count := range repos {
+			result[repo] += count
+		}
+	}
+	return result
+}
+
+func (snapshot Snapshot) organizationTotals(setting Setting) map[string]int {
+	var result map[string]int = make(map[string]int)
+	for domain, count := range snapshot.Domains {
+		if domain != "TOTAL" {
+			result[OrganizationOfDomain(setting, domain)] += count
+		}
+	}
+	return result
+}
+
+func unionKeys(before map[string]int, after map[string]int) []string {
+	var result []string
+	for key := range before {
+		result = append(result, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			result = append(result, key)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func changedRows(section string, before map[string]int, after map[string]int) []DiffRow {
+	var result []DiffRow
+	for _, key := range unionKeys(before, after) {
+		if before[key] != after[key] {
+			result = append(result, DiffRow{section, key, float64(before[key]), float64(after[key])})
+		}
+	}
+	return result
+}
+
+// DiffSnapshots lists what changed from before to after: contributors that
+// started or stopped committing, commit deltas per contributor, repository
+// and organization, and shares that moved by at least threshold points.
+func DiffSnapshots(setting Setting, before Snapshot, after Snapshot, threshold float64) []DiffRow {
+	var result []DiffRow
+
+	var beforeContributors map[string]int = before.contributorTotals()
+	var afterContributors map[string]int = after.contributorTotals()
+	for _, contributor := range unionKeys(beforeContributors, afterContributors) {
+		var row DiffRow = DiffRow{"", contributor, float64(beforeContributors[contributor]), float64(afterContributors[contributor])}
+		if row.Before == 0 && row.After > 0 {
+			row.Section = "new contributor"
+			result = append(result, row)
+		} else if row.Before > 0 && row.After == 0 {
+			row.Section = "vanished contributor"
+			result = append(result, row)
+		}
+	}
+
+	result = append(result, changedRows("contributor", beforeContributors, afterContributors)...)
+	result = append(result, changedRows("repo", before.repoTotals(), after.repoTotals())...)
+	result = append(result, changedRows("organization", before.organizationTotals(setting), after.organizationTotals(setting))...)
+
+	var shares []string
+	for key := range before.Shares {
+		shares = append(shares, key)
+	}
+	for key := range after.Shares {
+		if _, ok := before.Shares[key]; !ok {
+			shares = append(shares, key)
+		}
+	}
+	sort.Strings(shares)
+	for _, key := range shares {
+		if math.Abs(after.Shares[key]-before.Shares[key]) >= threshold {
+			result = append(result, DiffRow{"share", key, before.Shares[key], after.Shares[key]})
+		}
+	}
+	return result
+}
+
+func (setting Setting) diff() Diff {
+	var result Diff = setting.Diff
+	if result.Threshold == 0 {
+		result.Threshold = defaultShareThreshold
+	}
+	return result
+}
+
+// resolveSnapshot accepts either a snapshot name or a directory path.
+func resolveSnapshot(name string) (string, error) {
+	for _, dir := range []string{filepath.Join(snapshotDir, name), name} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", fmt.Errorf("no snapshot %q in %s", name, snapshotDir)
+}
+
+// RunDiff compares the two given snapshots, or the last two without
+// arguments, and writes the result to work/diff.csv.
+func RunDiff(setting Setting, args []string) error {
+	if len(args) == 0 {
+		var snapshots []string = ListSnapshots(snapshotDir)
+		if len(snapshots) < 2 {
+			return fmt.Errorf("need two snapshots in %s to diff, found %d", snapshotDir, len(snapshots))
+		}
+		args = snapshots[len(snapshots)-2:]
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: commit-count diff <snapshotA> <snapshotB>")
+	}
+
+	var dirs []string
+	for _, arg := range args {
+		dir, err := resolveSnapshot(arg)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, dir)
+	}
+
+	fmt.Printf("Comparing %s with %s\n", dirs[0], dirs[1])
+	CreateDiffOutputFile(DiffSnapshots(setting, ReadSnapshot(dirs[0]), ReadSnapshot(dirs[1]), setting.diff().Threshold))
+	return nil
+}
+
+func formatDiffValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func CreateDiffOutputFile(rows []DiffRow) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Section", "Name", "Before", "After", "Delta"})
+	for _, row := range rows {
+		var delta string = formatDiffValue(math.Round(row.Delta()*10) / 10)
+		if row.Delta() > 0 {
+			delta = "+" + delta
+		}
+		writer.Write([]string{row.Section, row.Name, formatDiffValue(row.Before), formatDiffValue(row.After), delta})
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/diff.csv", buffer.Bytes(), 0644)
+}
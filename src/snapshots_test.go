@@ -0,0 +1,93 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeSnapshotFile(t *testing.T, dir string, name string, content string) {
+	assert.Nil(t, os.MkdirAll(dir, 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+}
+
+func TestSaveSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commit-count-snapshots")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	var workDir string = filepath.Join(dir, "work")
+	writeSnapshotFile(t, workDir, "result.csv", ",Bosh\nVictor Fong,3\n")
+
+	name, err := SaveSnapshot(workDir, filepath.Join(dir, "snapshots"), time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, "20160102-030405.000", name)
+
+	// Runs within the same second keep their own snapshot
+	_, err = SaveSnapshot(workDir, filepath.Join(dir, "snapshots"), time.Date(2016, 1, 2, 3, 4, 5, 250*1000*1000, time.UTC))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"20160102-030405.000", "20160102-030405.250"}, ListSnapshots(filepath.Join(dir, "snapshots")))
+
+	_, err = SaveSnapshot(workDir, filepath.Join(dir, "snapshots"), time.Date(2016, 1, 2, 3, 4, 5, 0, time.UTC))
+	assert.NotNil(t, err)
+
+	var snapshot Snapshot = ReadSnapshot(filepath.Join(dir, "snapshots", name))
+	assert.Equal(t, 3, snapshot.Commits["Victor Fong"]["Bosh"])
+	assert.Equal(t, 0, len(snapshot.Domains))
+}
+
+func TestReadSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "commit-count-snapshot")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	writeSnapshotFile(t, dir, "result.csv", ",Bosh,Diego\nVictor Fong,3,1\nYu Zhang,0,2\n")
+	writeSnapshotFile(t, dir, "total_count.csv", "emc.com,4\nTOTAL,10\n")
+	writeSnapshotFile(t, dir, "share.csv", "Window,Code Repo,Organization,Rank,Commits,Share\noverall,Bosh,EMC,1,4,40.0\n")
+
+	var snapshot Snapshot = ReadSnapshot(dir)
+	assert.Equal(t, 1, snapshot.Commits["Victor Fong"]["Diego"])
+	assert.Equal(t, 2, snapshot.Commits["Yu Zhang"]["Diego"])
+	assert.Equal(t, 4, snapshot.Domains["emc.com"])
+	assert.Equal(t, 40.0, snapshot.Shares["overall/Bosh/EMC"])
+}
+
+func TestDiffSnapshots(t *testing.T) {
+	var setting Setting = Setting{Organizations: []Organization{{Name: "EMC", Domains: []string{"emc.com"}}}}
+	var before Snapshot = Snapshot{
+		Commits: map[string]map[string]int{
+			"Victor Fong": {"Bosh": 3},
+			"Yu Zhang":    {"Bosh": 2},
+		},
+		Domains: map[string]int{"emc.com": 3, "pivotal.io": 2, "TOTAL": 5},
+		Shares:  map[string]float64{"overall/Bosh/EMC": 60, "overall/Bosh/pivotal.io": 40},
+	}
+	var after Snapshot = Snapshot{
+		Commits: map[string]map[string]int{
+			"Victor Fong":   {"Bosh": 5},
+			"Yu Zhang":      {"Bosh": 0},
+			"Chris Piraino": {"Bosh": 1},
+		},
+		Domains: map[string]int{"emc.com": 5, "pivotal.io": 3, "TOTAL": 8},
+		Shares:  map[string]float64{"overall/Bosh/EMC": 62.5, "overall/Bosh/pivotal.io": 37.5},
+	}
+
+	assert.Equal(t, []DiffRow{
+		{"new contributor", "Chris Piraino", 0, 1},
+		{"vanished contributor", "Yu Zhang", 2, 0},
+		{"contributor", "Chris Piraino", 0, 1},
+		{"contributor", "Victor Fong", 3, 5},
+		{"contributor", "Yu Zhang", 2, 0},
+		{"repo", "Bosh", 5, 6},
+		{"organization", "EMC", 3, 5},
+		{"organization", "pivotal.io", 2, 3},
+	}, DiffSnapshots(setting, before, after, 5))
+
+	var rows []DiffRow = DiffSnapshots(setting, before, after, 2)
+	assert.Equal(t, DiffRow{"share", "overall/Bosh/pivotal.io", 40, 37.5}, rows[len(rows)-1])
+	assert.Equal(t, -2.5, rows[len(rows)-1].Delta())
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Survival configures commit-count survival. Lines added in each of the
+// last Quarters complete quarters are followed for every number of Months,
+// by blaming the repository as it was that long after the quarter ended.
+type Survival struct {
+	Months   []int
+	Quarters int
+}
+
+var defaultSurvival Survival = Survival{Months: []int{3, 6, 12, 24}, Quarters: 8}
+
+type SurvivalOwner struct {
+	OwnerType string
+	Owner     string
+}
+
+// owner -> quarter -> lines
+type QuarterLines map[SurvivalOwner]map[string]int
+
+func (lines QuarterLines) add(owner SurvivalOwner, quarter string, count int) {
+	if lines[owner] == nil {
+		lines[owner] = make(map[string]int)
+	}
+	lines[owner][quarter] += count
+}
+
+func quarterStart(date time.Time) time.Time {
+	var month time.Month = date.Month() - (date.Month()-1)%3
+	return time.Date(date.Year(), month, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func QuarterOf(date time.Time) string {
+	return fmt.Sprintf("%dQ%d", date.Year(), (int(date.Month())+2)/3)
+}
+
+// LastQuarters returns the start of the count complete quarters before now,
+// oldest first.
+func LastQuarters(now time.Time, count int) []time.Time {
+	var result []time.Time
+	var start time.Time = quarterStart(now)
+	for i := count; i >= 1; i-- {
+		result = append(result, start.AddDate(0, -3*i, 0))
+	}
+	return result
+}
+
+// SurvivalCheckpoints lists the distinct dates to blame at: every quarter
+// end plus every number of months, as long as it is not in the future.
+func SurvivalCheckpoints(quarters []time.Time, months []int, now time.Time) []time.Time {
+	var seen map[time.Time]bool = make(map[time.Time]bool)
+	var result []time.Time
+	for _, start := range quarters {
+		for _, month := range months {
+			var checkpoint time.Time = start.AddDate(0, 3+month, 0)
+			if checkpoint.After(now) || seen[checkpoint] {
+				continue
+			}
+			seen[checkpoint] = true
+			result = append(result, checkpoint)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// survivalOwners credits the commit author, as blame only knows authors.
+func survivalOwners(setting Setting, commit GitCommit) []SurvivalOwner {
+	var result []SurvivalOwner
+	if isEmcCommit, contributorName := IsEmcCommit(GitCommit{Author: commit.Author}, setting.Contributors); isEmcCommit {
+		result = append(result, SurvivalOwner{"contributor", contributorName})
+	}
+	return append(result, SurvivalOwner{"organization", OrganizationOfDomain(setting, commit.AuthorDomain)})
+}
+
+// CountIntroduced sums the lines added per owner and quarter, only in the
+// files blame counts.
+func CountIntroduced(setting Setting, commits []GitCommit, include []string, exclude []string) QuarterLines {
+	var result QuarterLines = make(QuarterLines)
+	for _, commit := range commits {
+		var added int = 0
+		for _, file := range commit.Files {
+			if IncludesPath(file.Path, include, exclude) {
+				added += file.Added
+			}
+		}
+		if added == 0 {
+			continue
+		}
+		for _, owner := range survivalOwners(setting, commit) {
+			result.add(owner, QuarterOf(dayOf(commit.Date)), added)
+		}
+	}
+	return result
+}
+
+// CountSurviving adds the lines of git blame --line-porcelain output per
+// owner and the quarter the line was written in.
+func CountSurviving(setting Setting, output string, result QuarterLines) {
+	scanBlamePorcelain(output, func(author BlameAuthor, authorTime time.Time) {
+		for _, owner := range survivalOwners(setting, author.AsCommit()) {
+			result.add(owner, QuarterOf(dayOf(authorTime)), 1)
+		}
+	})
+}
+
+type SurvivalPoint struct {
+	Quarter    string
+	Months     int
+	Introduced int
+	Surviving  int
+}
+
+// BuildSurvivalCurves pairs the introduced lines of each owner and quarter
+// with what survived at each checkpoint. surviving is keyed by checkpoint.
+func BuildSurvivalCurves(introduced QuarterLines, surviving map[time.Time]QuarterLines,
+	quarters []time.Time, months []int) map[SurvivalOwner][]SurvivalPoint {
+	var result map[SurvivalOwner][]SurvivalPoint = make(map[SurvivalOwner][]SurvivalPoint)
+	for owner, byQuarter := range introduced {
+		for _, start := range quarters {
+			var quarter string = QuarterOf(start)
+			if byQuarter[quarter] == 0 {
+				continue
+			}
+			for _, month := range months {
+				lines, ok := surviving[start.AddDate(0, 3+month, 0)]
+				if !ok {
+					continue
+				}
+				result[owner] = append(result[owner], SurvivalPoint{
+					Quarter:    quarter,
+					Months:     month,
+					Introduced: byQuarter[quarter],
+					Surviving:  lines[owner][quarter],
+				})
+			}
+		}
+	}
+	return result
+}
+
+// HalfLife is the number of months after which half of the lines are gone,
+// interpolated over the survival of all quarters together. It is false when
+// more than half survived at every checkpoint.
+func HalfLife(points []SurvivalPoint, months []int) (float64, bool) {
+	var previousMonths float64 = 0
+	var previousSurvival float64 = 1
+	for _, month := range months {
+		var introduced, surviving int = 0, 0
+		for _, point := range points {
+			if point.Months == month {
+				introduced += point.Introduced
+				surviving += point.Surviving
+			}
+		}
+		if introduced == 0 {
+			continue
+		}
+
+		var survival float64 = float64(surviving) / float64(introduced)
+		if survival <= 0.5 {
+			var ratio float64 = (previousSurvival - 0.5) / (previousSurvival - survival)
+			return previousMonths + ratio*(float64(month)-previousMonths), true
+		}
+		previousMonths, previousSurvival = float64(month), survival
+	}
+	return 0, false
+}
+
+func (setting Setting) survival() Survival {
+	var result Survival = setting.Survival
+	if len(result.Months) == 0 {
+		result.Months = defaultSurvival.Months
+	}
+	sort.Ints(result.Months)
+	if result.Quarters == 0 {
+		result.Quarters = defaultSurvival.Quarters
+	}
+	return result
+}
+
+// RefHistory parses git log --numstat of the blamed ref only, so lines of
+// unmerged or abandoned branches, which can never survive, are not counted
+// as introduced.
+func RefHistory(dir string, repoName string, ref string) ([]GitCommit, error) {
+	var args []string = append(append([]string{"log"}, logFormatArgs...), ref, "--")
+	output, err := gitOutput(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ReadCommit(bufio.NewScanner(strings.NewReader(output)), repoName), nil
+}
+
+// SurviveRepository blames the included files of the repository at every
+// checkpoint.
+func SurviveRepository(setting Setting, dir string, ref string, include []string, exclude []string,
+	checkpoints []time.Time) (map[time.Time]QuarterLines, error) {
+	var result map[time.Time]QuarterLines = make(map[time.Time]QuarterLines)
+	for _, checkpoint := range checkpoints {
+		result[checkpoint] = make(QuarterLines)
+
+		output, err := gitOutput(dir, "rev-list", "-1", "--before="+checkpoint.Format(time.RFC3339), ref)
+		if err != nil {
+			return nil, err
+		}
+		var revision string = strings.TrimSpace(output)
+		if revision == "" {
+			continue
+		}
+
+		err = blameEachFile(dir, revision, include, exclude, func(output string) {
+			CountSurviving(setting, output, result[checkpoint])
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// RunSurvival writes survival curves to work/survival.csv and half-lives to
+// work/half_life.csv for every configured repository.
+func RunSurvival(setting Setting, options RunOptions, now time.Time) {
+	var survival Survival = setting.survival()
+	var blame Blame = setting.blame()
+	var quarters []time.Time = LastQuarters(now, survival.Quarters)
+	var checkpoints []time.Time = SurvivalCheckpoints(quarters, survival.Months, now)
+
+	var result map[string]map[SurvivalOwner][]SurvivalPoint = make(map[string]map[SurvivalOwner][]SurvivalPoint)
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	for _, repo := range setting.Repositories {
+		wg.Add(1)
+		go func(repo1 Repository) {
+			defer wg.Done()
+
+			if !options.Offline {
+				if err := fetchSource(repo1); err != nil {
+					fmt.Printf("ERROR FETCH: %s\n", repo1.Name)
+					panic(err)
+				}
+			}
+
+			commits, err := RefHistory(repoDir(repo1), repo1.Name, blame.Ref)
+			if err != nil {
+				fmt.Printf("WARNING: unable to log %s at %s: %s\n", repo1.Name, blame.Ref, err)
+				return
+			}
+			var introduced QuarterLines = CountIntroduced(setting, commits, repo1.Include, blame.excludeFor(repo1))
+
+			fmt.Printf("Blaming %s at %d checkpoints\n", repo1.Name, len(checkpoints))
+			surviving, err := SurviveRepository(setting, repoDir(repo1), blame.Ref, repo1.Include, blame.excludeFor(repo1), checkpoints)
+			if err != nil {
+				fmt.Printf("WARNING: unable to blame %s: %s\n", repo1.Name, err)
+				return
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result[repo1.Name] = BuildSurvivalCurves(introduced, surviving, quarters, survival.Months)
+		}(repo)
+	}
+	wg.Wait()
+
+	CreateSurvivalOutputFiles(result, survival.Months)
+}
+
+func sortedOwners(curves map[SurvivalOwner][]SurvivalPoint) []SurvivalOwner {
+	var result []SurvivalOwner
+	for owner := range curves {
+		result = append(result, owner)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].OwnerType != result[j].OwnerType {
+			return result[i].OwnerType < result[j].OwnerType
+		}
+		return result[i].Owner < result[j].Owner
+	})
+	return result
+}
+
+func CreateSurvivalOutputFiles(result map[string]map[SurvivalOwner][]SurvivalPoint, months []int) {
+	var curveBuffer bytes.Buffer
+	var halfLifeBuffer bytes.Buffer
+	curveWriter := csv.NewWriter(&curveBuffer)
+	halfLifeWriter := csv.NewWriter(&halfLifeBuffer)
+
+	var repoNames []string
+	for repoName := range result {
+		repoNames = append(repoNames, repoName)
+	}
+	sort.Strings(repoNames)
+
+	curveWriter.Write([]string{"Code Repo", "Owner Type", "Owner", "Quarter", "Introduced", "Months", "Surviving", "Survival"})
+	halfLifeWriter.Write([]string{"Code Repo", "Owner Type", "Owner", "Half Life Months"})
+	for _, repoName := range repoNames {
+		for _, owner := range sortedOwners(result[repoName]) {
+			var points []SurvivalPoint = result[repoName][owner]
+			for _, point := range points {
+				curveWriter.Write([]string{repoName, owner.OwnerType, owner.Owner, point.Quarter,
+					strconv.Itoa(point.Introduced), strconv.Itoa(point.Months), strconv.Itoa(point.Surviving),
+					percentOf(point.Surviving, point.Introduced)})
+			}
+
+			var halfLife string = ">" + strconv.Itoa(months[len(months)-1])
+			if value, ok := HalfLife(points, months); ok {
+				halfLife = formatShare(value)
+			}
+			halfLifeWriter.Write([]string{repoName, owner.OwnerType, owner.Owner, halfLife})
+		}
+	}
+	curveWriter.Flush()
+	halfLifeWriter.Flush()
+
+	fmt.Print(halfLifeBuffer.String())
+	ioutil.WriteFile("work/survival.csv", curveBuffer.Bytes(), 0644)
+	ioutil.WriteFile("work/half_life.csv", halfLifeBuffer.Bytes(), 0644)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuarterOf(t *testing.T) {
+	assert.Equal(t, "2015Q4", QuarterOf(time.Date(2015, 10, 16, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2016Q1", QuarterOf(time.Date(2016, 3, 31, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "2016Q2", QuarterOf(time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestLastQuarters(t *testing.T) {
+	var result []time.Time = LastQuarters(time.Date(2016, 5, 10, 0, 0, 0, 0, time.UTC), 2)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+	}, result)
+}
+
+func TestSurvivalCheckpoints(t *testing.T) {
+	var quarters []time.Time = []time.Time{
+		time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	var result []time.Time = SurvivalCheckpoints(quarters, []int{3, 6}, time.Date(2016, 8, 1, 0, 0, 0, 0, time.UTC))
+
+	assert.Equal(t, []time.Time{
+		time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2016, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, result)
+}
+
+func TestCountIntroduced(t *testing.T) {
+	var setting Setting = Setting{
+		Contributors:  []Contributor{{Name: "Victor Fong"}},
+		Organizations: []Organization{{Name: "EMC", Domains: []string{"emc.com"}}},
+	}
+	var commits []GitCommit = []GitCommit{
+		{Author: "Victor Fong", AuthorDomain: "emc.com", Date: time.Date(2015, 10, 16, 0, 0, 0, 0, time.UTC),
+			Files: []FileChange{{Path: "README.md", Added: 3}, {Path: "vendor/lib.go", Added: 100}}},
+		{Author: "Chris Piraino", AuthorDomain: "pivotal.io", Date: time.Date(2016, 1, 5, 0, 0, 0, 0, time.UTC),
+			Files: []FileChange{{Path: "main.go", Added: 5}}},
+	}
+	var result QuarterLines = CountIntroduced(setting, commits, nil, []string{"vendor/**"})
+
+	assert.Equal(t, 3, result[SurvivalOwner{"contributor", "Victor Fong"}]["2015Q4"])
+	assert.Equal(t, 3, result[SurvivalOwner{"organization", "EMC"}]["2015Q4"])
+	assert.Equal(t, 5, result[SurvivalOwner{"organization", "pivotal.io"}]["2016Q1"])
+	assert.Equal(t, 3, len(result))
+
+	// Only the repository's included paths count, as in blame
+	result = CountIntroduced(setting, commits, []string{"*.go"}, []string{"vendor/**"})
+	assert.Equal(t, 0, result[SurvivalOwner{"organization", "EMC"}]["2015Q4"])
+	assert.Equal(t, 5, result[SurvivalOwner{"organization", "pivotal.io"}]["2016Q1"])
+}
+
+func TestCountSurviving(t *testing.T) {
+	var setting Setting = Setting{Contributors: []Contributor{{Name: "Victor Fong"}}}
+	var result QuarterLines = make(QuarterLines)
+	CountSurviving(setting, testBlamePorcelain, result)
+
+	assert.Equal(t, 2, result[SurvivalOwner{"contributor", "Victor Fong"}]["2015Q4"])
+	assert.Equal(t, 1, result[SurvivalOwner{"organization", "Other"}]["2015Q4"])
+}
+
+func TestBuildSurvivalCurves(t *testing.T) {
+	var owner SurvivalOwner = SurvivalOwner{"contributor", "Victor Fong"}
+	var start time.Time = time.Date(2015, 10, 1, 0, 0, 0, 0, time.UTC)
+	var introduced QuarterLines = QuarterLines{owner: {"2015Q4": 10}}
+	var surviving map[time.Time]QuarterLines = map[time.Time]QuarterLines{
+		time.Date(2016, 4, 1, 0, 0, 0, 0, time.UTC): {owner: {"2015Q4": 8}},
+	}
+	var result map[SurvivalOwner][]SurvivalPoint = BuildSurvivalCurves(introduced, surviving, []time.Time{start}, []int{3, 6})
+
+	assert.Equal(t, []SurvivalPoint{{Quarter: "2015Q4", Months: 3, Introduced: 10, Surviving: 8}}, result[owner])
+}
+
+func TestHalfLife(t *testing.T) {
+	var points []SurvivalPoint = []SurvivalPoint{
+		{Quarter: "2015Q4", Months: 3, Introduced: 10, Surviving: 8},
+		{Quarter: "2015Q4", Months: 6, Introduced: 10, Surviving: 2},
+	}
+
+	halfLife, ok := HalfLife(points, []int{3, 6})
+	assert.True(t, ok)
+	assert.InDelta(t, 4.5, halfLife, 0.001)
+
+	_, ok = HalfLife(points[:1], []int{3, 6})
+	assert.False(t, ok)
+}
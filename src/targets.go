@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Target is a contribution goal for the named window. It applies to exactly
+// one repository, group or contributor. Metrics left at zero have no goal.
+type Target struct {
+	Repository   string
+	Group        string
+	Contributor  string
+	Window       string
+	Commits      int
+	PullRequests int `yaml:"pull_requests"`
+	Churn        int
+}
+
+func (target Target) scope() (string, string) {
+	if target.Repository != "" {
+		return "repository", target.Repository
+	}
+	if target.Group != "" {
+		return "group", target.Group
+	}
+	return "contributor", target.Contributor
+}
+
+// validateScope checks that each name that is set is configured, so a typo
+// fails the run instead of silently matching no commits.
+func (setting Setting) validateScope(repository string, group string, contributor string) error {
+	if repository != "" {
+		var found bool = false
+		for _, repo := range setting.Repositories {
+			found = found || repo.Name == repository
+		}
+		if !found {
+			return fmt.Errorf("no repository %s", repository)
+		}
+	}
+	if group != "" {
+		var found bool = false
+		for _, configured := range setting.Groups {
+			found = found || configured.Name == group
+		}
+		if !found {
+			return fmt.Errorf("no group %s", group)
+		}
+	}
+	if contributor != "" {
+		var found bool = false
+		for _, configured := range setting.Contributors {
+			found = found || configured.Name == contributor
+		}
+		if !found {
+			return fmt.Errorf("no contributor %s", contributor)
+		}
+	}
+	return nil
+}
+
+func validateTargets(setting Setting) error {
+	for _, target := range setting.Targets {
+		var scopes int = 0
+		for _, name := range []string{target.Repository, target.Group, target.Contributor} {
+			if name != "" {
+				scopes++
+			}
+		}
+		if scopes != 1 {
+			return fmt.Errorf("target for window %s: set exactly one of repository, group and contributor", target.Window)
+		}
+
+		if _, ok := findWindow(setting.Windows, target.Window); !ok {
+			return fmt.Errorf("target for window %s: no such window", target.Window)
+		}
+		if err := setting.validateScope(target.Repository, target.Group, target.Contributor); err != nil {
+			return fmt.Errorf("target for window %s: %s", target.Window, err)
+		}
+	}
+	return nil
+}
+
+func findWindow(windows []Window, name string) (Window, bool) {
+	for _, window := range windows {
+		if window.Name == name {
+			return window, true
+		}
+	}
+	return Window{}, false
+}
+
+type TargetProgress struct {
+	Commits      int
+	PullRequests int
+	Churn        int
+}
+
+// isHandle compares a handle, which may be written as "@victorfong", with a
+// pull request fork owner.
+func isHandle(handle string, owner string) bool {
+	handle = strings.TrimPrefix(handle, "@")
+	return handle != "" && strings.EqualFold(handle, owner)
+}
+
+// isContributorHandle reports whether a pull request fork owner is one of
+// the configured contributors.
+func isContributorHandle(setting Setting, owner string) bool {
+	for _, contributor := range setting.Contributors {
+		if isHandle(contributor.Handle, owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeRepositories lists the history keys of a repository, of the members
+// of a group or, with neither, of every repository.
+func scopeRepositories(setting Setting, repository string, groupName string, history map[string][]GitCommit) []string {
+	var result []string
+	if repository != "" {
+		return []string{repository}
+	}
+	if groupName != "" {
+		for _, group := range setting.Groups {
+			if group.Name != groupName {
+				continue
+			}
+			for _, repo := range GroupMembers(setting, group) {
+				result = append(result, repo.Name)
+			}
+		}
+		return result
+	}
+	for repoName := range history {
+		result = append(result, repoName)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CountTargetProgress counts what the target's contributors did within its
+// window: commits and churn of configured contributors, or of the one
+// contributor, and pull requests opened from their forks. Pull requests come
+// from unfiltered, the history before path filters, which keeps the merges.
+func CountTargetProgress(setting Setting, target Target, history map[string][]GitCommit,
+	unfiltered map[string][]GitCommit) TargetProgress {
+	var result TargetProgress
+	window, _ := findWindow(setting.Windows, target.Window)
+
+	for _, repoName := range scopeRepositories(setting, target.Repository, target.Group, history) {
+		var seen map[int]bool = make(map[int]bool)
+		for _, commit := range CommitsInWindow(unfiltered[repoName], window) {
+			if commit.PullRequest == 0 || seen[commit.PullRequest] {
+				continue
+			}
+			seen[commit.PullRequest] = true
+			if target.Contributor != "" {
+				if isHandle(contributorHandle(setting, target.Contributor), commit.PullRequestOwner) {
+					result.PullRequests++
+				}
+			} else if isContributorHandle(setting, commit.PullRequestOwner) {
+				result.PullRequests++
+			}
+		}
+
+		for _, commit := range CommitsInWindow(history[repoName], window) {
+			var contributors []string = CommitContributors(commit, setting.Contributors)
+			if len(contributors) == 0 || (target.Contributor != "" && !contains(contributors, target.Contributor)) {
+				continue
+			}
+			result.Commits++
+			result.Churn += commitChurn(commit)
+		}
+	}
+	return result
+}
+
+// ProjectToEnd extrapolates actual to the end of the window at the rate
+// since the window began. Windows that are over keep their actual value.
+func ProjectToEnd(actual int, window Window, now time.Time) int {
+	beginDate, endDate := window.Dates()
+	if !now.Before(endDate) {
+		return actual
+	}
+
+	var elapsed float64 = now.Sub(beginDate).Hours()
+	if elapsed <= 0 {
+		return actual
+	}
+	return int(math.Round(float64(actual) * endDate.Sub(beginDate).Hours() / elapsed))
+}
+
+// CreateTargetOutputFile writes one row per target and metric with a goal.
+func CreateTargetOutputFile(setting Setting, history map[string][]GitCommit, unfiltered map[string][]GitCommit,
+	now time.Time) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Scope", "Name", "Window", "Metric", "Target", "Actual", "Complete", "Projected"})
+	for _, target := range setting.Targets {
+		var progress TargetProgress = CountTargetProgress(setting, target, history, unfiltered)
+		window, _ := findWindow(setting.Windows, target.Window)
+		scope, name := target.scope()
+
+		for _, metric := range []struct {
+			name   string
+			target int
+			actual int
+		}{
+			{"commits", target.Commits, progress.Commits},
+			{"pull requests", target.PullRequests, progress.PullRequests},
+			{"churn", target.Churn, progress.Churn},
+		} {
+			if metric.target == 0 {
+				continue
+			}
+			writer.Write([]string{scope, name, window.Name, metric.name, strconv.Itoa(metric.target),
+				strconv.Itoa(metric.actual), percentOf(metric.actual, metric.target),
+				strconv.Itoa(ProjectToEnd(metric.actual, window, now))})
+		}
+	}
+	writer.Flush()
+
+	fmt.Print(buffer.String())
+	ioutil.WriteFile("work/targets.csv", buffer.Bytes(), 0644)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var test_targets_data = `
+---
+windows:
+- name: 2015Q4
+  begin: "2015-09-30"
+  end: "2016-01-01"
+groups:
+- name: BOSH
+  repositories:
+  - Bosh
+repositories:
+- name: Bosh
+  url: https://github.com/cloudfoundry/bosh.git
+- name: Diego
+  url: https://github.com/cloudfoundry/diego-release.git
+contributors:
+- name: Victor Fong
+  handle: "@victorfong"
+- name: Yu Zhang
+targets:
+- group: BOSH
+  window: 2015Q4
+  commits: 4
+  pull_requests: 2
+- contributor: Victor Fong
+  window: 2015Q4
+  churn: 100
+`
+
+var test_targets_history = map[string][]GitCommit{
+	"Bosh": {
+		{Author: "Victor Fong", Date: time.Date(2015, 10, 16, 0, 0, 0, 0, time.UTC),
+			Files: []FileChange{{Path: "README.md", Added: 10, Deleted: 2}}},
+		{Author: "Yu Zhang", Date: time.Date(2015, 11, 2, 0, 0, 0, 0, time.UTC),
+			Files: []FileChange{{Path: "main.go", Added: 5}}},
+		{Author: "Chris Piraino", Date: time.Date(2015, 11, 3, 0, 0, 0, 0, time.UTC)},
+		{Author: "Dmitriy Kalinin", Date: time.Date(2015, 11, 4, 0, 0, 0, 0, time.UTC),
+			PullRequest: 1024, PullRequestOwner: "VictorFong"},
+		{Author: "Victor Fong", Date: time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC)},
+	},
+	"Diego": {
+		{Author: "Victor Fong", Date: time.Date(2015, 12, 1, 0, 0, 0, 0, time.UTC),
+			Files: []FileChange{{Path: "main.go", Added: 20}}},
+	},
+}
+
+func TestUnmarshalYaml_Targets(t *testing.T) {
+	setting, err := UnmarshalYaml([]byte(test_targets_data))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, len(setting.Targets))
+	assert.Equal(t, 2, setting.Targets[0].PullRequests)
+
+	_, err = UnmarshalYaml([]byte("targets:\n- repository: Bosh\n  window: 2015Q1\n"))
+	assert.NotNil(t, err)
+
+	_, err = UnmarshalYaml([]byte("targets:\n- repository: Bosh\n  group: BOSH\n  window: overall\n"))
+	assert.NotNil(t, err)
+
+	for _, scope := range []string{"repository: Boshh", "group: BOHS", "contributor: Victor Fnog"} {
+		_, err = UnmarshalYaml([]byte(strings.Replace(test_targets_data, "contributor: Victor Fong", scope, 1)))
+		assert.NotNil(t, err, scope)
+	}
+}
+
+func TestCountTargetProgress(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_targets_data))
+
+	assert.Equal(t, TargetProgress{Commits: 2, PullRequests: 1, Churn: 17},
+		CountTargetProgress(setting, setting.Targets[0], test_targets_history, test_targets_history))
+	assert.Equal(t, TargetProgress{Commits: 2, PullRequests: 1, Churn: 32},
+		CountTargetProgress(setting, setting.Targets[1], test_targets_history, test_targets_history))
+}
+
+func TestCountTargetProgress_PathFiltered(t *testing.T) {
+	setting, _ := UnmarshalYaml([]byte(test_targets_data))
+
+	// An include filter drops the merge of pull request 1024, which has no
+	// file list, from history but not from the unfiltered commits
+	var history map[string][]GitCommit = map[string][]GitCommit{
+		"Bosh": FilterCommitsByPath(test_targets_history["Bosh"], []string{"*.go"}, nil),
+	}
+	assert.Equal(t, TargetProgress{Commits: 1, PullRequests: 1, Churn: 5},
+		CountTargetProgress(setting, setting.Targets[0], history, test_targets_history))
+}
+
+func TestProjectToEnd(t *testing.T) {
+	var window Window = Window{Name: "2016Q1", Begin: "2016-01-01", End: "2016-01-11"}
+
+	assert.Equal(t, 20, ProjectToEnd(5, window, time.Date(2016, 1, 3, 12, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 5, ProjectToEnd(5, window, time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 0, ProjectToEnd(0, window, time.Date(2015, 12, 1, 0, 0, 0, 0, time.UTC)))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Window is a named date range, e.g. a quarter. Begin and End are
+// YYYY-MM-DD; like CountOverallCommit, both days are excluded.
+type Window struct {
+	Name  string
+	Begin string
+	End   string
+}
+
+// reportingLocation is the timezone commits are bucketed into days, weeks
+// and windows in. When nil, each commit keeps its author's own offset.
+var reportingLocation *time.Location
+
+func reportingTime(date time.Time) time.Time {
+	if reportingLocation == nil {
+		return date
+	}
+	return date.In(reportingLocation)
+}
+
+func (setting Setting) location() (*time.Location, error) {
+	if setting.Timezone == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(setting.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %s: %s", setting.Timezone, err)
+	}
+	return location, nil
+}
+
+// The range total_count.csv has always covered.
+var defaultWindows []Window = []Window{{Name: "overall", Begin: "2015-05-31", End: "2016-01-01"}}
+
+func (window Window) Dates() (time.Time, time.Time) {
+	return getDate(window.Begin), getDate(window.End)
+}
+
+func validateWindows(windows []Window) error {
+	for _, window := range windows {
+		for _, date := range []string{window.Begin, window.End} {
+			if _, err := time.Parse("2006-01-02", date); err != nil {
+				return fmt.Errorf("window %s: %s", window.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CommitsInWindow keeps the commits whose day is strictly between the
+// window's dates.
+func CommitsInWindow(commits []GitCommit, window Window) []GitCommit {
+	beginDate, endDate := window.Dates()
+
+	var result []GitCommit
+	for _, commit := range commits {
+		if day := dayOf(commit.Date); day.After(beginDate) && day.Before(endDate) {
+			result = append(result, commit)
+		}
+	}
+	return result
+}